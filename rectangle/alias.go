@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parsePackageAliases parses "A:Alpha,B:Beta" into an identifier -> alias map
+func parsePackageAliases(s string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	if strings.TrimSpace(s) == "" {
+		return aliases, nil
+	}
+	for _, tok := range strings.Split(s, ",") {
+		id, alias, ok := strings.Cut(strings.TrimSpace(tok), ":")
+		if !ok || id == "" || alias == "" {
+			return nil, fmt.Errorf("package alias %q: want ID:alias", tok)
+		}
+		if _, dup := aliases[id]; dup {
+			return nil, fmt.Errorf("package %s is aliased twice", id)
+		}
+		aliases[id] = alias
+	}
+	return aliases, nil
+}
+
+// applyAliases renames the result's selected identifiers for output; the
+// optimizer and its selection keep the original identifiers
+func (r *OptimizationResult) applyAliases(aliases map[string]string) {
+	for i, id := range r.Selected {
+		if alias, ok := aliases[id]; ok {
+			r.Selected[i] = alias
+		}
+	}
+}
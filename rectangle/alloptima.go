@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// maxAllOptima caps how many optimal selections AllOptimalSelections returns
+const maxAllOptima = 100
+
+// backtrackState is a partial recovery: items i..n-1 decided, w capacity left
+type backtrackState struct {
+	i, w   int
+	chosen []PackageMetadata
+}
+
+// AllOptimalSelections enumerates up to maxAllOptima selections reaching
+// dp[n][capacity]. It walks the backtracking tree breadth-first, branching
+// wherever both skipping and taking item i keep the optimum. Every state has
+// at least one such move, so trimming the frontier to the cap never leaves
+// fewer than the cap's worth of complete selections.
+func AllOptimalSelections(dp [][]int, pkgs []PackageMetadata, capacity int) [][]PackageMetadata {
+	frontier := []backtrackState{{i: len(pkgs), w: capacity}}
+	for i := len(pkgs); i > 0; i-- {
+		var next []backtrackState
+		for _, s := range frontier {
+			wt, val := pkgs[i-1].MassConstraint, pkgs[i-1].Valuation
+			if dp[i][s.w] == dp[i-1][s.w] {
+				next = append(next, backtrackState{i: i - 1, w: s.w, chosen: s.chosen})
+			}
+			if wt <= s.w && dp[i][s.w] == dp[i-1][s.w-wt]+val {
+				chosen := append(append([]PackageMetadata(nil), s.chosen...), pkgs[i-1])
+				next = append(next, backtrackState{i: i - 1, w: s.w - wt, chosen: chosen})
+			}
+		}
+		if len(next) > maxAllOptima {
+			next = next[:maxAllOptima]
+		}
+		frontier = next
+	}
+
+	selections := make([][]PackageMetadata, len(frontier))
+	for k, s := range frontier {
+		selections[k] = s.chosen
+	}
+	return selections
+}
+
+// CountOptimalSolutions counts the distinct subsets reaching the optimum. Next
+// to the value DP it keeps cnt[w], the number of subsets of the items so far
+// with mass at most w that reach the best value for w; taking and skipping
+// item i give disjoint subsets, so their counts add when both tie. Counts
+// saturate at math.MaxInt64.
+func CountOptimalSolutions(pkgs []PackageMetadata, capacity int) int64 {
+	best := make([]int, capacity+1)
+	cnt := make([]int64, capacity+1)
+	for w := range cnt {
+		cnt[w] = 1 // the empty selection
+	}
+	for _, p := range pkgs {
+		for w := capacity; w >= p.MassConstraint; w-- {
+			take := best[w-p.MassConstraint] + p.Valuation
+			switch {
+			case take > best[w]:
+				best[w], cnt[w] = take, cnt[w-p.MassConstraint]
+			case take == best[w]:
+				if cnt[w] > math.MaxInt64-cnt[w-p.MassConstraint] {
+					cnt[w] = math.MaxInt64
+				} else {
+					cnt[w] += cnt[w-p.MassConstraint]
+				}
+			}
+		}
+	}
+	return cnt[capacity]
+}
+
+// printAllOptima writes a numbered list of every optimal selection
+func printAllOptima(w io.Writer, pkgs []PackageMetadata, ctx HeuristicContext) {
+	selections := AllOptimalSelections(buildTable(pkgs, ctx.MaxLoad), pkgs, ctx.MaxLoad)
+	for k, sel := range selections {
+		fmt.Fprintf(w, "%3d. %s (mass %s, value %s)\n", k+1, formatSelection(sel), num(totalMass(sel)), num(totalValue(sel)))
+	}
+	if len(selections) == maxAllOptima {
+		fmt.Fprintf(w, "stopped at %d selections; more may exist\n", maxAllOptima)
+	}
+}
+
+// nearOptimalLimit is how many near-optimal selections are shown
+const nearOptimalLimit = 10
+
+// searchAtLeast visits every selection worth at least *threshold. It decides
+// take/skip from the last item down, pruning a branch when its value so far
+// plus dp[i][w], the best the remaining items could add, falls short. visit
+// receives a reused slice, may raise *threshold, and returns false to stop.
+func searchAtLeast(dp [][]int, pkgs []PackageMetadata, capacity int, threshold *int, visit func([]PackageMetadata) bool) {
+	var chosen []PackageMetadata
+	stopped := false
+	var search func(i, w, value int)
+	search = func(i, w, value int) {
+		if stopped || value+dp[i][w] < *threshold {
+			return
+		}
+		if i == 0 {
+			stopped = !visit(chosen)
+			return
+		}
+		p := pkgs[i-1]
+		if p.MassConstraint <= w {
+			chosen = append(chosen, p)
+			search(i-1, w-p.MassConstraint, value+p.Valuation)
+			chosen = chosen[:len(chosen)-1]
+		}
+		search(i-1, w, value)
+	}
+	search(len(pkgs), capacity, 0)
+}
+
+// NearOptimalSelections returns the nearOptimalLimit most valuable
+// selections worth at least (1 - percent/100) of the optimum, best first;
+// once the list is full, the threshold rises to the value of its worst entry
+func NearOptimalSelections(pkgs []PackageMetadata, capacity int, percent float64) [][]PackageMetadata {
+	dp := buildTable(pkgs, capacity)
+	threshold := int(math.Ceil(float64(dp[len(pkgs)][capacity]) * (1 - percent/100)))
+
+	var found [][]PackageMetadata
+	searchAtLeast(dp, pkgs, capacity, &threshold, func(chosen []PackageMetadata) bool {
+		found = append(found, append([]PackageMetadata(nil), chosen...))
+		sort.SliceStable(found, func(a, b int) bool { return totalValue(found[a]) > totalValue(found[b]) })
+		if len(found) > nearOptimalLimit {
+			found = found[:nearOptimalLimit]
+		}
+		if len(found) == nearOptimalLimit {
+			threshold = max(threshold, totalValue(found[nearOptimalLimit-1])+1)
+		}
+		return true
+	})
+	return found
+}
+
+// maxNearOptimal caps how many selections EnumerateNearOptimal returns
+const maxNearOptimal = 1000
+
+// EnumerateNearOptimal returns up to maxNearOptimal feasible selections worth
+// at least the optimum minus tolerance, best first
+func EnumerateNearOptimal(pkgs []PackageMetadata, capacity, tolerance int) [][]PackageMetadata {
+	dp := buildTable(pkgs, capacity)
+	threshold := dp[len(pkgs)][capacity] - tolerance
+
+	var found [][]PackageMetadata
+	searchAtLeast(dp, pkgs, capacity, &threshold, func(chosen []PackageMetadata) bool {
+		found = append(found, append([]PackageMetadata(nil), chosen...))
+		return len(found) < maxNearOptimal
+	})
+	sort.SliceStable(found, func(a, b int) bool { return totalValue(found[a]) > totalValue(found[b]) })
+	return found
+}
+
+// printEnumerateNearOptimal writes every selection within tolerance of the optimum
+func printEnumerateNearOptimal(w io.Writer, pkgs []PackageMetadata, ctx HeuristicContext, tolerance int) {
+	optimum := optimalValue(pkgs, ctx)
+	selections := EnumerateNearOptimal(pkgs, ctx.MaxLoad, tolerance)
+	for k, sel := range selections {
+		v := totalValue(sel)
+		fmt.Fprintf(w, "%4d. %s (mass %s, value %s, gap %s)\n", k+1, formatSelection(sel), num(totalMass(sel)), num(v), num(optimum-v))
+	}
+	if len(selections) == maxNearOptimal {
+		fmt.Fprintf(w, "stopped at %d selections; more may exist\n", maxNearOptimal)
+	}
+}
+
+// printNearOptimal writes the near-optimal selections with their gap to the optimum
+func printNearOptimal(w io.Writer, pkgs []PackageMetadata, ctx HeuristicContext, percent float64) {
+	optimum := optimalValue(pkgs, ctx)
+	for k, sel := range NearOptimalSelections(pkgs, ctx.MaxLoad, percent) {
+		v := totalValue(sel)
+		fmt.Fprintf(w, "%2d. %s (mass %s, value %s, %s%% of optimal)\n", k+1, formatSelection(sel), num(totalMass(sel)), num(v), fnum(100*float64(v)/float64(max(optimum, 1)), 1))
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// optimalValue returns the DP optimum for the given packages
+func optimalValue(pkgs []PackageMetadata, ctx HeuristicContext) int {
+	return totalValue((&PriorityBasedOptimizer{}).Optimize(pkgs, ctx))
+}
+
+// withoutIndex returns a copy of pkgs with the i-th package removed
+func withoutIndex(pkgs []PackageMetadata, i int) []PackageMetadata {
+	out := make([]PackageMetadata, 0, len(pkgs)-1)
+	out = append(out, pkgs[:i]...)
+	return append(out, pkgs[i+1:]...)
+}
+
+// RemovalImpact records the optimum when a single package is unavailable
+type RemovalImpact struct {
+	Identifier string
+	Value      int
+	Loss       int
+}
+
+// LeaveOneOut recomputes the optimum with each package removed in turn,
+// sorted so the most critical package comes first
+func LeaveOneOut(pkgs []PackageMetadata, ctx HeuristicContext) []RemovalImpact {
+	baseline := optimalValue(pkgs, ctx)
+	impacts := make([]RemovalImpact, len(pkgs))
+	for i, pkg := range pkgs {
+		value := optimalValue(withoutIndex(pkgs, i), ctx)
+		impacts[i] = RemovalImpact{Identifier: pkg.Identifier, Value: value, Loss: baseline - value}
+	}
+	sort.SliceStable(impacts, func(i, j int) bool {
+		return impacts[i].Loss > impacts[j].Loss
+	})
+	return impacts
+}
+
+// printLeaveOneOut writes the leave-one-out report, marking the most critical packages
+func printLeaveOneOut(w io.Writer, pkgs []PackageMetadata, ctx HeuristicContext) {
+	impacts := LeaveOneOut(pkgs, ctx)
+	fmt.Fprintf(w, "baseline optimum: %s\n", num(optimalValue(pkgs, ctx)))
+	for _, impact := range impacts {
+		marker := ""
+		if impact.Loss > 0 && impact.Loss == impacts[0].Loss {
+			marker = "  <- most critical"
+		}
+		fmt.Fprintf(w, "without %-3s value=%-4s loss=%s%s\n", impact.Identifier, num(impact.Value), num(impact.Loss), marker)
+	}
+}
+
+// ItemContribution is a selected package's marginal value: the optimum with
+// every package available minus the optimum without this one
+type ItemContribution struct {
+	Package  PackageMetadata
+	Without  int
+	Marginal int
+}
+
+// ItemContributions re-solves the DP without each selected package in turn
+func ItemContributions(pkgs, selected []PackageMetadata, ctx HeuristicContext) []ItemContribution {
+	baseline := optimalValue(pkgs, ctx)
+	index := make(map[string]int, len(pkgs))
+	for i, p := range pkgs {
+		index[p.Identifier] = i
+	}
+	contributions := make([]ItemContribution, 0, len(selected))
+	for _, p := range selected {
+		without := optimalValue(withoutIndex(pkgs, index[p.Identifier]), ctx)
+		contributions = append(contributions, ItemContribution{Package: p, Without: without, Marginal: baseline - without})
+	}
+	return contributions
+}
+
+// printItemContributions writes one row per selected package with its marginal value
+func printItemContributions(w io.Writer, pkgs, selected []PackageMetadata, ctx HeuristicContext) {
+	fmt.Fprintf(w, "%-4s %6s %6s %12s %8s\n", "pkg", "mass", "value", "optimum w/o", "marginal")
+	for _, c := range ItemContributions(pkgs, selected, ctx) {
+		fmt.Fprintf(w, "%-4s %6s %6s %12s %8s\n", c.Package.Identifier, num(c.Package.MassConstraint), num(c.Package.Valuation), num(c.Without), num(c.Marginal))
+	}
+}
+
+// printWhatIfCapacity compares the optimum at the current capacity and at capacity+delta
+func printWhatIfCapacity(w io.Writer, pkgs []PackageMetadata, ctx HeuristicContext, delta int) {
+	dp := &PriorityBasedOptimizer{}
+	before := dp.Optimize(pkgs, ctx)
+	larger := ctx
+	larger.MaxLoad += delta
+	after := dp.Optimize(pkgs, larger)
+
+	gain := totalValue(after) - totalValue(before)
+	fmt.Fprintf(w, "capacity %s: value %s\n", num(ctx.MaxLoad), num(totalValue(before)))
+	fmt.Fprintf(w, "capacity %s: value %s\n", num(larger.MaxLoad), num(totalValue(after)))
+	if delta != 0 {
+		fmt.Fprintf(w, "marginal value: %s per unit of capacity\n", fnum(float64(gain)/float64(delta), 2))
+	}
+
+	was := make(map[string]bool, len(before))
+	for _, p := range before {
+		was[p.Identifier] = true
+	}
+	added := false
+	for _, p := range after {
+		if !was[p.Identifier] {
+			note := ""
+			if p.MassConstraint > ctx.MaxLoad {
+				note = " (did not fit at all before)"
+			}
+			fmt.Fprintf(w, "newly selected: %s%s\n", p.Identifier, note)
+			added = true
+		}
+	}
+	if !added {
+		fmt.Fprintln(w, "newly selected: none")
+	}
+}
+
+// SecondBest returns the best selection that differs from the optimal one.
+// Any other selection either omits an optimal item or adds a non-optimal one,
+// so it is the best of n restricted DPs: each optimal item forbidden in turn,
+// and each other item forced in (solved with its mass reserved).
+func SecondBest(pkgs []PackageMetadata, ctx HeuristicContext) (optimal, second []PackageMetadata, ok bool) {
+	dp := &PriorityBasedOptimizer{}
+	optimal = dp.Optimize(pkgs, ctx)
+	inOptimal := make(map[string]bool, len(optimal))
+	for _, p := range optimal {
+		inOptimal[p.Identifier] = true
+	}
+
+	bestValue := 0
+	for i, p := range pkgs {
+		rest := withoutIndex(pkgs, i)
+		var candidate []PackageMetadata
+		if inOptimal[p.Identifier] {
+			candidate = dp.Optimize(rest, ctx)
+		} else {
+			if p.MassConstraint > ctx.MaxLoad {
+				continue
+			}
+			reduced := ctx
+			reduced.MaxLoad -= p.MassConstraint
+			candidate = append(dp.Optimize(rest, reduced), p)
+		}
+		if v := totalValue(candidate); !ok || v > bestValue {
+			second, bestValue, ok = candidate, v, true
+		}
+	}
+	return optimal, second, ok
+}
+
+// printSecondBest writes the optimum, the runner-up selection and the gap between them
+func printSecondBest(w io.Writer, pkgs []PackageMetadata, ctx HeuristicContext) {
+	optimal, second, ok := SecondBest(pkgs, ctx)
+	fmt.Fprintf(w, "optimal:     %s (value %s)\n", formatSelection(optimal), num(totalValue(optimal)))
+	if !ok {
+		fmt.Fprintln(w, "second best: none (no other selection exists)")
+		return
+	}
+	fmt.Fprintf(w, "second best: %s (value %s)\n", formatSelection(second), num(totalValue(second)))
+	fmt.Fprintf(w, "gap:         %s\n", num(totalValue(optimal)-totalValue(second)))
+}
+
+// PriorityMatrix returns m[i][j], the extra optimum gained by having both
+// packages i and j available on top of the rest of the catalog, compared with
+// having only the better of the two: v(R+i+j) - max(v(R+i), v(R+j)) where R
+// is the catalog without i and j and v is the knapsack optimum. Large values
+// mark pairs the optimum relies on together; zero marks a pair where either
+// one can stand in for both. This is a cheap stand-in for the Shapley
+// interaction index, evaluated only at the full coalition. The diagonal is 0.
+func PriorityMatrix(pkgs []PackageMetadata, ctx HeuristicContext) [][]int {
+	full := optimalValue(pkgs, ctx)
+	// v(R+i) is the optimum without j, and vice versa
+	without := make([]int, len(pkgs))
+	for k := range pkgs {
+		without[k] = optimalValue(withoutIndex(pkgs, k), ctx)
+	}
+	m := make([][]int, len(pkgs))
+	for i := range m {
+		m[i] = make([]int, len(pkgs))
+		for j := range m[i] {
+			if i != j {
+				m[i][j] = full - max(without[i], without[j])
+			}
+		}
+	}
+	return m
+}
+
+// printPriorityMatrix writes the symmetric pairwise matrix with identifier headers
+func printPriorityMatrix(w io.Writer, pkgs []PackageMetadata, ctx HeuristicContext) {
+	m := PriorityMatrix(pkgs, ctx)
+	fmt.Fprintf(w, "%-4s", "")
+	for _, p := range pkgs {
+		fmt.Fprintf(w, " %5s", p.Identifier)
+	}
+	fmt.Fprintln(w)
+	for i, p := range pkgs {
+		fmt.Fprintf(w, "%-4s", p.Identifier)
+		for j := range pkgs {
+			if i == j {
+				fmt.Fprintf(w, " %5s", "-")
+			} else {
+				fmt.Fprintf(w, " %5d", m[i][j])
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// printDPComparison writes the heuristic selection next to the exact DP
+// optimum and the relative gap between their values
+func printDPComparison(w io.Writer, pkgs, selected []PackageMetadata, ctx HeuristicContext) {
+	optimal := (&PriorityBasedOptimizer{}).Optimize(pkgs, ctx)
+	h, o := totalValue(selected), totalValue(optimal)
+	gap := 0.0
+	if o > 0 {
+		gap = 100 * float64(o-h) / float64(o)
+	}
+	fmt.Fprintf(w, "Heuristic: %s (value=%s)  |  DP Optimal: %s (value=%s)  |  Gap: %s%%\n",
+		formatSelection(slices.Clone(selected)), num(h), formatSelection(optimal), num(o), fnum(gap, 1))
+}
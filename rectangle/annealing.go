@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// SimulatedAnnealingOptimizer searches selections with random single-package
+// flips, accepting worse neighbours with probability exp(delta/T) while the
+// temperature T cools geometrically
+type SimulatedAnnealingOptimizer struct {
+	Iterations  int
+	InitialTemp float64
+	Cooling     float64
+	Rand        *rand.Rand
+
+	// OnProgress, when set, is called after every iteration with the best value so far
+	OnProgress func(iter, best int)
+	// OnMove, when set, is called with every proposed flip
+	OnMove func(AnnealMove)
+}
+
+// AnnealMove is one proposed flip; Probability is 0 for moves that overflow
+// the capacity and 1 for moves that do not lose value
+type AnnealMove struct {
+	Temperature float64
+	Value       int
+	Proposed    int
+	Delta       int
+	Probability float64
+	Accepted    bool
+}
+
+// NewSimulatedAnnealingOptimizer returns an annealer with default schedule
+func NewSimulatedAnnealingOptimizer(seed int64) *SimulatedAnnealingOptimizer {
+	return &SimulatedAnnealingOptimizer{
+		Iterations:  5000,
+		InitialTemp: 100,
+		Cooling:     0.999,
+		Rand:        rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Optimize anneals from the empty load and returns the best feasible selection seen
+func (o *SimulatedAnnealingOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	in := make([]bool, len(pkgs))
+	best := make([]bool, len(pkgs))
+	mass, value, bestValue := 0, 0, 0
+	temp := o.InitialTemp
+
+	for iter := 0; iter < o.Iterations; iter++ {
+		k := o.Rand.Intn(len(pkgs))
+		dm, dv := pkgs[k].MassConstraint, pkgs[k].Valuation
+		if in[k] {
+			dm, dv = -dm, -dv
+		}
+
+		feasible := mass+dm <= ctx.MaxLoad
+		prob := 0.0
+		switch {
+		case !feasible:
+		case dv >= 0:
+			prob = 1
+		default:
+			prob = math.Exp(float64(dv) / temp)
+		}
+		accepted := feasible && (dv >= 0 || o.Rand.Float64() < prob)
+		if o.OnMove != nil {
+			o.OnMove(AnnealMove{Temperature: temp, Value: value, Proposed: value + dv, Delta: dv, Probability: prob, Accepted: accepted})
+		}
+		if accepted {
+			in[k] = !in[k]
+			mass += dm
+			value += dv
+			if value > bestValue {
+				bestValue = value
+				copy(best, in)
+			}
+		}
+
+		temp *= o.Cooling
+		if o.OnProgress != nil {
+			o.OnProgress(iter, bestValue)
+		}
+	}
+
+	var selected []PackageMetadata
+	for i, ok := range best {
+		if ok {
+			selected = append(selected, pkgs[i])
+		}
+	}
+	return selected
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+)
+
+// runConfig is the configuration recorded in result archives
+type runConfig struct {
+	Email          string  `json:"email"`
+	Algorithm      string  `json:"algorithm"`
+	MaxLoad        int     `json:"max_load"`
+	PriorityFactor float64 `json:"priority_factor"`
+}
+
+// writeArchive bundles the catalog, configuration and result into a zip at path
+func writeArchive(path string, pkgs []PackageMetadata, cfg runConfig, result OptimizationResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(f)
+
+	entries := []struct {
+		name string
+		v    any
+	}{
+		{"catalog.json", pkgs},
+		{"config.json", cfg},
+		{"result.json", result},
+	}
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(e.v); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// readEmails reads one email per line from path ("-" for stdin), skipping blanks and # comments
+func readEmails(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	// a bufio.Reader rather than a Scanner, whose 64 KiB line limit would
+	// fail the whole batch on one overlong email instead of that email alone
+	var emails []string
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			emails = append(emails, line)
+		}
+		if err == io.EOF {
+			return emails, nil
+		}
+		if err != nil {
+			return emails, err
+		}
+	}
+}
+
+// runBatch optimizes every email in the batch file, printing "email<TAB>result" lines
+func runBatch(opts *options) int {
+	emails, err := readEmails(opts.batch)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "batch:", err)
+		return 1
+	}
+	for i, email := range emails {
+		emails[i] = opts.seedEmail(email)
+	}
+
+	if opts.seedExport != "" {
+		if err := exportSeeds(opts.seedExport, emails, opts.domainWeight); err != nil {
+			fmt.Fprintln(os.Stderr, "seed export:", err)
+			return 1
+		}
+	}
+
+	var limiter *tokenBucket
+	if opts.submitURL != "" && opts.submitRateLimit > 0 {
+		limiter = newTokenBucket(opts.submitRateLimit)
+		defer limiter.Stop()
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	generator := NewEmailBasedPackageGenerator()
+	generator.DomainWeight = opts.domainWeight
+	optimizer := newOptimizerChain(opts)
+	ctx, _ := opts.heuristicContext()
+	// results reference the true truck size, reserve included, as in run
+	truck := ctx
+	truck.MaxLoad += opts.reserve
+	failed := 0
+	metrics := &batchMetrics{emails: len(emails), start: time.Now()}
+	for _, email := range emails {
+		if err := opts.checkEmailLength(email); err != nil {
+			errorf("%.40s...: %v", email, err)
+			failed++
+			continue
+		}
+		pkgs, err := preprocess(opts, generator.Generate(email))
+		if err != nil {
+			errorf("%s: %v", email, err)
+			failed++
+			continue
+		}
+		began := time.Now()
+		selected := optimizer.Optimize(pkgs, ctx)
+		metrics.optimizing += time.Since(began)
+		metrics.optimizations++
+		if err := optimizer.failure(selected); err != nil {
+			errorf("%s: %v", email, err)
+			failed++
+			continue
+		}
+		metrics.value += totalValue(selected)
+		result := newResult(email, opts.algo, truck, selected)
+		result.PackageSetHash = PackageSetHash(pkgs)
+		result.applyAliases(opts.aliases)
+		switch {
+		case opts.compactOutput:
+			fmt.Println(strings.Join(result.Selected, ","))
+		case len(selected) == 0:
+			fmt.Printf("%s\t%s\n", email, formatSelection(selected))
+		default:
+			fmt.Printf("%s\t%s\n", email, strings.Join(result.Selected, ","))
+		}
+
+		if opts.submitURL != "" {
+			if limiter != nil {
+				if waited := limiter.Wait(); waited > 0 {
+					infof("throttled submission for %s by %s", email, waited.Round(time.Millisecond))
+				}
+			}
+			if _, _, err := submitResult(client, opts.submitURL, nil, result); err != nil {
+				errorf("%v", err)
+				failed++
+			}
+		}
+	}
+	if opts.metricsFile != "" {
+		metrics.failures = failed
+		if err := writeMetricsFile(opts.metricsFile, metrics); err != nil {
+			fmt.Fprintln(os.Stderr, "metrics file:", err)
+			return 1
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d emails failed\n", failed, len(emails))
+		return 1
+	}
+	return 0
+}
+
+// writeSeedExport writes email,seed,x_mass,x_value,y_mass,y_value rows, with
+// seeds and packages generated under -domain-weight domainWeight
+func writeSeedExport(w io.Writer, emails []string, domainWeight uint64) error {
+	generator := NewEmailBasedPackageGenerator()
+	generator.DomainWeight = domainWeight
+	base := len(generator.basePackages)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"email", "seed", "x_mass", "x_value", "y_mass", "y_value"})
+	for _, email := range emails {
+		pkgs := generator.Generate(email)
+		x, y := pkgs[base], pkgs[base+1]
+		cw.Write([]string{
+			email,
+			strconv.FormatUint(computeWeightedSeed(email, domainWeight), 10),
+			strconv.Itoa(x.MassConstraint), strconv.Itoa(x.Valuation),
+			strconv.Itoa(y.MassConstraint), strconv.Itoa(y.Valuation),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportSeeds writes the seed export CSV for emails to path
+func exportSeeds(path string, emails []string, domainWeight uint64) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeSeedExport(f, emails, domainWeight); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what f writes to os.Stdout
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	f()
+	w.Close()
+	return <-done
+}
+
+// TestBatchMatchesSingleRuns checks that -batch solves each email with the
+// same load limit and optimizer as a run for that email alone
+func TestBatchMatchesSingleRuns(t *testing.T) {
+	emails := []string{"test@example.com", "a@b.c", "someone@example.org"}
+	dir := t.TempDir()
+	batch := filepath.Join(dir, "emails.txt")
+	if err := os.WriteFile(batch, []byte(strings.Join(emails, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, flags := range [][]string{
+		{"-reserve", "10"},
+		{"-capacity-flex-percent", "20"},
+		{"-mass-parity", "odd"},
+		{"-total-cost-constraint", "20"},
+		{"-force-include", "C"},
+		{"-algo", "greedy", "-force-include", "C,E", "-capacity", "55"},
+		{"-timeout", "5s"},
+	} {
+		var got []string
+		out := captureStdout(t, func() {
+			if code := run(append(append([]string{"-quiet", "-batch", batch}, flags...), "-compact-output")); code != 0 {
+				t.Errorf("%v: batch run = %d", flags, code)
+			}
+		})
+		got = strings.Split(strings.TrimSpace(out), "\n")
+		if len(got) != len(emails) {
+			t.Fatalf("%v: batch printed %q, want one line per email", flags, out)
+		}
+		for i, email := range emails {
+			path := filepath.Join(dir, "result.json")
+			captureStdout(t, func() {
+				if code := run(append(append([]string{"-quiet", "-json-file", path}, flags...), email)); code != 0 {
+					t.Errorf("%v %s: run = %d", flags, email, code)
+				}
+			})
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var result OptimizationResult
+			if err := json.Unmarshal(data, &result); err != nil {
+				t.Fatal(err)
+			}
+			if want := strings.Join(result.Selected, ","); got[i] != want {
+				t.Errorf("%v %s: batch selected %s, single run %s", flags, email, got[i], want)
+			}
+		}
+	}
+}
+
+func TestBatchReportsInfeasible(t *testing.T) {
+	batch := filepath.Join(t.TempDir(), "emails.txt")
+	if err := os.WriteFile(batch, []byte("test@example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() {
+		if code := run([]string{"-quiet", "-batch", batch, "-mass-parity", "odd", "-capacity", "1"}); code != 1 {
+			t.Errorf("infeasible parity in -batch exited %d, want 1", code)
+		}
+	})
+}
+
+func TestSeedExportDomainWeight(t *testing.T) {
+	const email, weight = "test@example.com", 7
+	var buf strings.Builder
+	if err := writeSeedExport(&buf, []string{email}, weight); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	generator := NewEmailBasedPackageGenerator()
+	generator.DomainWeight = weight
+	x := generator.Generate(email)[len(generator.basePackages)]
+	want := []string{email, strconv.FormatUint(computeWeightedSeed(email, weight), 10), strconv.Itoa(x.MassConstraint), strconv.Itoa(x.Valuation)}
+	if len(rows) != 2 || !slices.Equal(rows[1][:4], want) {
+		t.Errorf("exported %v, want a row starting %v", rows, want)
+	}
+	if computeWeightedSeed(email, weight) == computeSeed(email) {
+		t.Fatal("domain weight does not change the seed for this email; pick another")
+	}
+}
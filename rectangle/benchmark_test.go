@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+var (
+	checkBenchmarks = flag.Bool("benchmark-regression", false, "run TestBenchmarkRegression against testdata/benchmarks.json")
+	updateGolden    = flag.Bool("update-golden", false, "rewrite testdata/benchmarks.json with the measured times")
+)
+
+// goldenBenchmarks is where TestBenchmarkRegression keeps its reference times
+var goldenBenchmarks = filepath.Join("testdata", "benchmarks.json")
+
+// benchmarkRuns is how often each instance is solved per round; the fastest
+// run is kept so that scheduler noise only ever makes a measurement slower
+const benchmarkRuns = 5
+
+// benchmarkRetries is how often an instance over its limit is re-measured
+// before the test fails
+const benchmarkRetries = 3
+
+// benchmarkInstance is one fixed DP problem: n synthetic packages at capacity W
+type benchmarkInstance struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	W    int    `json:"w"`
+	Seed uint64 `json:"seed"`
+	// Ratio is the instance's solve time in units of calibrate's time, so
+	// that the golden survives a slower or busier machine
+	Ratio float64 `json:"ratio"`
+}
+
+// benchmarkInstances are the 20 problems timed by TestBenchmarkRegression,
+// from a few hundred to a few million DP cells
+func benchmarkInstances() []benchmarkInstance {
+	var out []benchmarkInstance
+	for i := 0; i < 20; i++ {
+		n := 20 + 20*(i%5)
+		W := 500 * (1 + i/5) * (1 + i/5)
+		out = append(out, benchmarkInstance{
+			Name: fmt.Sprintf("n%d_w%d", n, W),
+			N:    n,
+			W:    W,
+			Seed: uint64(1000 + i),
+		})
+	}
+	return out
+}
+
+// calibrationSink keeps calibrate's loops from being optimized away
+var calibrationSink int
+
+// calibrate is a fixed workload with the memory footprint of a rows x cols
+// DP table that does not touch the optimizer; instance times are measured
+// relative to it, so cache and memory bandwidth noise affect both alike
+func calibrate(rows, cols int) {
+	table := make([][]int, rows)
+	table[0] = make([]int, cols)
+	for i := 1; i < rows; i++ {
+		table[i] = make([]int, cols)
+		for w := range table[i] {
+			table[i][w] = max(table[i-1][w], table[i-1][w/2]+i&7)
+		}
+	}
+	calibrationSink = table[rows-1][cols-1]
+}
+
+// fastest returns the quickest of benchmarkRuns calls to f
+func fastest(f func()) time.Duration {
+	best := time.Duration(-1)
+	for r := 0; r < benchmarkRuns; r++ {
+		start := time.Now()
+		f()
+		if d := time.Since(start); best < 0 || d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+// timeInstance returns the fastest DP solve of inst divided by the fastest
+// calibrate run, measuring the two interleaved so both see the same load
+func timeInstance(inst benchmarkInstance) float64 {
+	pkgs := appendSynthetic(nil, inst.N, inst.W/4, inst.Seed)
+	ctx := HeuristicContext{MaxLoad: inst.W, PriorityFactor: 1.0}
+	opt := &PriorityBasedOptimizer{}
+	solve, calib := time.Duration(-1), time.Duration(-1)
+	for round := 0; round < benchmarkRuns; round++ {
+		if d := fastest(func() { opt.Optimize(pkgs, ctx) }); solve < 0 || d < solve {
+			solve = d
+		}
+		if d := fastest(func() { calibrate(inst.N+1, inst.W+1) }); calib < 0 || d < calib {
+			calib = d
+		}
+	}
+	return float64(solve) / float64(calib)
+}
+
+// TestBenchmarkRegression fails when any instance takes more than 110% of
+// its golden time; run with -update-golden to record new reference times.
+// A 10% margin is within the run-to-run noise of shared machines, so the
+// test only runs when asked for with -benchmark-regression.
+func TestBenchmarkRegression(t *testing.T) {
+	if !*checkBenchmarks && !*updateGolden {
+		t.Skip("timing test; run with -benchmark-regression on a quiet machine")
+	}
+	instances := benchmarkInstances()
+	fastest(func() { calibrate(100, 10000) }) // warm up the heap and CPU before the first instance
+	if *updateGolden {
+		for i := range instances {
+			// the median keeps one unusually quiet measurement from
+			// setting a limit later runs cannot meet
+			ratios := make([]float64, 5)
+			for r := range ratios {
+				ratios[r] = timeInstance(instances[i])
+			}
+			slices.Sort(ratios)
+			instances[i].Ratio = ratios[len(ratios)/2]
+		}
+		data, err := json.MarshalIndent(instances, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goldenBenchmarks, append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("wrote %s", goldenBenchmarks)
+		return
+	}
+
+	data, err := os.ReadFile(goldenBenchmarks)
+	if err != nil {
+		t.Fatalf("%v (run with -update-golden to create it)", err)
+	}
+	var golden []benchmarkInstance
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("%s: %v", goldenBenchmarks, err)
+	}
+	byName := make(map[string]benchmarkInstance, len(golden))
+	for _, g := range golden {
+		byName[g.Name] = g
+	}
+	for _, inst := range instances {
+		g, ok := byName[inst.Name]
+		if !ok || g.N != inst.N || g.W != inst.W || g.Seed != inst.Seed {
+			t.Errorf("%s: no matching golden entry; run with -update-golden", inst.Name)
+			continue
+		}
+		// a noisy neighbour can slow one measurement; a regression slows all
+		got := timeInstance(inst)
+		for retry := 0; retry < benchmarkRetries && got > g.Ratio*1.1; retry++ {
+			got = min(got, timeInstance(inst))
+		}
+		if got > g.Ratio*1.1 {
+			t.Errorf("%s: %.3f calibration units exceeds 110%% of golden %.3f", inst.Name, got, g.Ratio)
+		}
+	}
+}
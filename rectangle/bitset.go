@@ -0,0 +1,59 @@
+package main
+
+import "math/big"
+
+// BitsetDP solves the 0/1 problem over reachable sets instead of best
+// values: row[v] is a bitset whose bit w is set when some subset of the
+// items so far has value exactly v and mass exactly w. Adding an item ORs
+// row[v-val] shifted left by its mass into row[v], masked to capacity. The
+// optimum is the largest v with any bit set. Rows after every item are kept
+// for recovery, so memory grows with n * totalValue * W bits.
+type BitsetDP struct{}
+
+// Optimize returns an optimal selection recovered from the bitset rows
+func (o *BitsetDP) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	W := ctx.MaxLoad
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(W+1)), big.NewInt(1))
+	maxValue := totalValue(pkgs)
+
+	// rows[i][v] holds the reachable masses of value v using the first i items
+	rows := make([][]*big.Int, len(pkgs)+1)
+	rows[0] = make([]*big.Int, maxValue+1)
+	for v := range rows[0] {
+		rows[0][v] = new(big.Int)
+	}
+	rows[0][0].SetBit(rows[0][0], 0, 1)
+	shifted := new(big.Int)
+	for i, p := range pkgs {
+		prev := rows[i]
+		row := make([]*big.Int, maxValue+1)
+		for v := range row {
+			row[v] = new(big.Int).Set(prev[v])
+			if v >= p.Valuation && prev[v-p.Valuation].Sign() != 0 {
+				shifted.Lsh(prev[v-p.Valuation], uint(p.MassConstraint))
+				row[v].Or(row[v], shifted.And(shifted, mask))
+			}
+		}
+		rows[i+1] = row
+	}
+
+	final := rows[len(pkgs)]
+	best := maxValue
+	for best > 0 && final[best].Sign() == 0 {
+		best--
+	}
+	// the lightest mass reaching the optimum
+	w := int(final[best].TrailingZeroBits())
+
+	res := []PackageMetadata{}
+	for i, v := len(pkgs), best; i > 0; i-- {
+		if rows[i-1][v].Bit(w) == 1 {
+			continue
+		}
+		p := pkgs[i-1]
+		res = append(res, p)
+		v -= p.Valuation
+		w -= p.MassConstraint
+	}
+	return res
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestBitsetDPMatchesIntDP(t *testing.T) {
+	generator := NewEmailBasedPackageGenerator()
+	for _, email := range []string{"test@example.com", "a@b.c", "someone@example.org"} {
+		pkgs := generator.Generate(email)
+		for _, W := range []int{0, 7, 25, 50, 200} {
+			ctx := HeuristicContext{MaxLoad: W}
+			want := totalValue((&PriorityBasedOptimizer{}).Optimize(pkgs, ctx))
+			selected := (&BitsetDP{}).Optimize(pkgs, ctx)
+			if got := totalValue(selected); got != want || totalMass(selected) > W {
+				t.Errorf("%s at %d: bitset value %d mass %d, int DP value %d", email, W, got, totalMass(selected), want)
+			}
+		}
+	}
+}
+
+// BenchmarkBitsetDP solves the standard 8-package catalog at capacity 50
+// with the math/big bitset DP (-compact-dp) and the integer DP
+func BenchmarkBitsetDP(b *testing.B) {
+	pkgs := NewEmailBasedPackageGenerator().Generate("test@example.com")
+	ctx := HeuristicContext{MaxLoad: defaultMaxLoad}
+	for _, tt := range []struct {
+		name string
+		opt  LoadOptimizer
+	}{{"int", &PriorityBasedOptimizer{}}, {"bitset", &BitsetDP{}}} {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				tt.opt.Optimize(pkgs, ctx)
+			}
+		})
+	}
+}
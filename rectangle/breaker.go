@@ -0,0 +1,87 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Circuit breaker defaults for NewCBOptimizer
+const (
+	breakerWindow   = 10               // calls remembered
+	breakerMaxSlow  = 3                // slow calls tolerated in the window
+	breakerSlow     = time.Second      // a call slower than this counts as slow
+	breakerCooldown = 30 * time.Second // open time before a trial call
+)
+
+// CBOptimizer wraps the DP in a circuit breaker. It remembers whether each
+// of the last breakerWindow calls was slow; when more than breakerMaxSlow
+// were, the circuit opens and calls return the greedy selection at once.
+// After breakerCooldown the circuit half-opens: the next call runs the DP
+// again and closes the circuit if it is fast, or reopens it if not.
+type CBOptimizer struct {
+	Inner    LoadOptimizer
+	Fallback LoadOptimizer
+
+	mu       sync.Mutex
+	slow     []bool // ring of recent outcomes, oldest first
+	openedAt time.Time
+	open     bool
+	now      func() time.Time
+}
+
+// NewCBOptimizer returns the breaker around a plain DP with greedy fallback
+func NewCBOptimizer() *CBOptimizer {
+	return &CBOptimizer{Inner: &PriorityBasedOptimizer{}, Fallback: &GreedyOptimizer{}, now: time.Now}
+}
+
+// Optimize runs Inner unless the circuit is open
+func (o *CBOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	o.mu.Lock()
+	trial := false
+	if o.open {
+		if o.now().Sub(o.openedAt) < breakerCooldown {
+			o.mu.Unlock()
+			warnf("circuit open after repeated slow optimizations; returning the greedy selection")
+			return o.Fallback.Optimize(pkgs, ctx)
+		}
+		trial = true
+	}
+	o.mu.Unlock()
+
+	start := o.now()
+	selected := o.Inner.Optimize(pkgs, ctx)
+	slow := o.now().Sub(start) > breakerSlow
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	switch {
+	case trial && slow:
+		o.openedAt = o.now()
+	case trial:
+		o.open = false
+		o.slow = o.slow[:0]
+		infof("circuit closed: trial optimization was fast")
+	default:
+		o.record(slow)
+	}
+	return selected
+}
+
+// record adds an outcome to the window and opens the circuit when too many
+// recent calls were slow; the caller holds mu
+func (o *CBOptimizer) record(slow bool) {
+	o.slow = append(o.slow, slow)
+	if len(o.slow) > breakerWindow {
+		o.slow = o.slow[1:]
+	}
+	count := 0
+	for _, s := range o.slow {
+		if s {
+			count++
+		}
+	}
+	if count > breakerMaxSlow {
+		o.open, o.openedAt = true, o.now()
+		warnf("circuit opened: %d of the last %d optimizations took over %s", count, len(o.slow), breakerSlow)
+	}
+}
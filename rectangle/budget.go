@@ -0,0 +1,52 @@
+package main
+
+// BudgetAwareOptimizer is the 2-D knapsack: maximize value with total mass
+// at most the capacity and total Cost at most Budget. dp[i][w][c] is the
+// best value from the first i items within mass w and cost c, stored flat
+// per row, so it costs O(n·W·Budget) time and memory. Packages with a
+// negative mass or cost are never selected.
+type BudgetAwareOptimizer struct {
+	Budget int
+}
+
+// Optimize returns an optimal selection within both limits
+func (o *BudgetAwareOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	n, W, B := len(pkgs), ctx.MaxLoad, o.Budget
+	cell := func(w, c int) int { return w*(B+1) + c }
+	dp := make([][]int, n+1)
+	for i := range dp {
+		dp[i] = make([]int, (W+1)*(B+1))
+	}
+	for i := 1; i <= n; i++ {
+		wt, cost, val := pkgs[i-1].MassConstraint, pkgs[i-1].Cost, pkgs[i-1].Valuation
+		for w := 0; w <= W; w++ {
+			for c := 0; c <= B; c++ {
+				best := dp[i-1][cell(w, c)]
+				if 0 <= wt && wt <= w && 0 <= cost && cost <= c {
+					best = max(best, dp[i-1][cell(w-wt, c-cost)]+val)
+				}
+				dp[i][cell(w, c)] = best
+			}
+		}
+	}
+
+	res := []PackageMetadata{}
+	for i, w, c := n, W, B; i > 0; i-- {
+		p := pkgs[i-1]
+		if 0 <= p.MassConstraint && p.MassConstraint <= w && 0 <= p.Cost && p.Cost <= c && dp[i][cell(w, c)] == dp[i-1][cell(w-p.MassConstraint, c-p.Cost)]+p.Valuation {
+			res = append(res, p)
+			w -= p.MassConstraint
+			c -= p.Cost
+		}
+	}
+	return res
+}
+
+// totalCost sums the costs of the given packages
+func totalCost(pkgs []PackageMetadata) int {
+	sum := 0
+	for _, p := range pkgs {
+		sum += p.Cost
+	}
+	return sum
+}
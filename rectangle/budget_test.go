@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// bruteForceBudget is the best value of any subset within both limits
+func bruteForceBudget(pkgs []PackageMetadata, W, B int) int {
+	best := 0
+	for mask := 0; mask < 1<<len(pkgs); mask++ {
+		mass, cost, value := 0, 0, 0
+		for i, p := range pkgs {
+			if mask&(1<<i) != 0 {
+				mass, cost, value = mass+p.MassConstraint, cost+p.Cost, value+p.Valuation
+			}
+		}
+		if mass <= W && cost <= B {
+			best = max(best, value)
+		}
+	}
+	return best
+}
+
+func TestBudgetAwareOptimizer(t *testing.T) {
+	for seed := uint64(1); seed <= 20; seed++ {
+		pkgs := appendSynthetic(nil, 10, 20, seed)
+		for _, budget := range []int{5, 20, 60} {
+			selected := (&BudgetAwareOptimizer{Budget: budget}).Optimize(pkgs, HeuristicContext{MaxLoad: 40})
+			if m, c := totalMass(selected), totalCost(selected); m > 40 || c > budget {
+				t.Errorf("seed %d, budget %d: mass %d, cost %d over the limits", seed, budget, m, c)
+			}
+			if got, want := totalValue(selected), bruteForceBudget(pkgs, 40, budget); got != want {
+				t.Errorf("seed %d, budget %d: value %d, want %d", seed, budget, got, want)
+			}
+		}
+	}
+}
+
+func TestBudgetAwareOptimizerNegativeCost(t *testing.T) {
+	pkgs := []PackageMetadata{
+		{Identifier: "A", MassConstraint: 10, Valuation: 60, Cost: 4},
+		{Identifier: "B", MassConstraint: 10, Valuation: 100, Cost: -3},
+	}
+	selected := (&BudgetAwareOptimizer{Budget: 5}).Optimize(pkgs, HeuristicContext{MaxLoad: 50})
+	if ids := identifiers(selected); len(ids) != 1 || ids[0] != "A" {
+		t.Errorf("selected %v, want [A]", ids)
+	}
+}
+
+func TestReadCatalogRejectsNegativeCost(t *testing.T) {
+	catalog := `[{"Identifier":"A","MassConstraint":10,"Valuation":60,"Cost":-3}]`
+	if _, err := readCatalog(strings.NewReader(catalog), "cost.json", false, false); err == nil || !strings.Contains(err.Error(), "cost") {
+		t.Errorf("readCatalog error = %v, want an invalid cost", err)
+	}
+}
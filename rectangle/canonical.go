@@ -0,0 +1,55 @@
+package main
+
+import "strings"
+
+// emailProvider describes how a mail provider folds address variants together
+type emailProvider struct {
+	domain      string // canonical domain
+	stripDots   bool   // dots in the local part are ignored
+	stripSuffix bool   // everything from the first + in the local part is ignored
+}
+
+// emailProviders lists the known providers by domain:
+//
+//	gmail.com, googlemail.com           dots and +tags ignored, domain gmail.com
+//	outlook.com, hotmail.com, live.com  +tags ignored
+//	icloud.com, me.com, mac.com         +tags ignored
+//	fastmail.com                        +tags ignored
+//	proton.me, protonmail.com           +tags ignored
+var emailProviders = map[string]emailProvider{
+	"gmail.com":      {domain: "gmail.com", stripDots: true, stripSuffix: true},
+	"googlemail.com": {domain: "gmail.com", stripDots: true, stripSuffix: true},
+	"outlook.com":    {domain: "outlook.com", stripSuffix: true},
+	"hotmail.com":    {domain: "hotmail.com", stripSuffix: true},
+	"live.com":       {domain: "live.com", stripSuffix: true},
+	"icloud.com":     {domain: "icloud.com", stripSuffix: true},
+	"me.com":         {domain: "me.com", stripSuffix: true},
+	"mac.com":        {domain: "mac.com", stripSuffix: true},
+	"fastmail.com":   {domain: "fastmail.com", stripSuffix: true},
+	"proton.me":      {domain: "proton.me", stripSuffix: true},
+	"protonmail.com": {domain: "protonmail.com", stripSuffix: true},
+}
+
+// canonicalEmail lowercases the email and applies its provider's rules from
+// emailProviders; other domains and emails without an @ are only lowercased
+func canonicalEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	provider, ok := emailProviders[domain]
+	if !ok {
+		return email
+	}
+	if provider.stripSuffix {
+		if plus := strings.IndexByte(local, '+'); plus >= 0 {
+			local = local[:plus]
+		}
+	}
+	if provider.stripDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + provider.domain
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// loadCatalog reads packages from a .json (array of PackageMetadata) or .csv
+// (identifier,mass,value) file. With skipBad, malformed CSV rows and invalid
+// JSON packages are logged and skipped instead of aborting the load. With strictJSON, anything but
+// whitespace after the JSON array is an error.
+func loadCatalog(path string, skipBad, strictJSON bool) ([]PackageMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readCatalog(f, path, skipBad, strictJSON)
+}
+
+// readCatalog parses a catalog from r, choosing JSON or CSV by name's extension
+func readCatalog(r io.Reader, name string, skipBad, strictJSON bool) ([]PackageMetadata, error) {
+	var pkgs []PackageMetadata
+	var skipped int
+	var err error
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		pkgs, skipped, err = decodeJSONCatalog(r, skipBad, strictJSON)
+	} else {
+		pkgs, skipped, err = parseCSVCatalog(r, skipBad)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if skipped > 0 {
+		warnf("%s: skipped %d malformed rows", name, skipped)
+	}
+	return pkgs, nil
+}
+
+// decodeJSONCatalog decodes a JSON array of packages and checks each like a
+// CSV row, skipping invalid ones when skipBad is set. Lenient mode ignores
+// anything after the array, which hides mistakes such as two arrays pasted
+// together; strict mode reports them.
+func decodeJSONCatalog(r io.Reader, skipBad, strict bool) ([]PackageMetadata, int, error) {
+	dec := json.NewDecoder(r)
+	var decoded []PackageMetadata
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, 0, err
+	}
+	if strict {
+		end := dec.InputOffset()
+		if _, err := dec.Token(); err != io.EOF {
+			return nil, 0, fmt.Errorf("unexpected data after catalog array (ends at byte %d)", end)
+		}
+	}
+
+	pkgs := decoded[:0]
+	skipped := 0
+	for i, pkg := range decoded {
+		if err := checkPackage(pkg); err != nil {
+			if !skipBad {
+				return nil, skipped, fmt.Errorf("package %d: %w", i+1, err)
+			}
+			warnf("catalog package %d: %v (skipped)", i+1, err)
+			skipped++
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, skipped, nil
+}
+
+// parseCSVCatalog parses identifier,mass,value[,category] rows, ignoring an optional header
+func parseCSVCatalog(r io.Reader, skipBad bool) ([]PackageMetadata, int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var pkgs []PackageMetadata
+	skipped := 0
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var perr *csv.ParseError
+		if err != nil && !errors.As(err, &perr) {
+			return nil, skipped, err // I/O failure, not a malformed row
+		}
+		if err == nil && line == 1 && len(rec) > 0 && strings.EqualFold(rec[0], "identifier") {
+			continue
+		}
+
+		var pkg PackageMetadata
+		if err == nil {
+			pkg, err = parseCatalogRow(rec)
+		}
+		if err != nil {
+			if !skipBad {
+				return nil, skipped, fmt.Errorf("line %d: %w", line, err)
+			}
+			warnf("catalog line %d: %v (skipped)", line, err)
+			skipped++
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, skipped, nil
+}
+
+// parseCatalogRow converts one identifier,mass,value[,category] record
+func parseCatalogRow(rec []string) (PackageMetadata, error) {
+	if len(rec) != 3 && len(rec) != 4 {
+		return PackageMetadata{}, fmt.Errorf("want 3 or 4 fields (identifier, mass, value[, category]), got %d", len(rec))
+	}
+	mass, err := strconv.Atoi(strings.TrimSpace(rec[1]))
+	if err != nil {
+		return PackageMetadata{}, fmt.Errorf("invalid mass %q", rec[1])
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(rec[2]))
+	if err != nil {
+		return PackageMetadata{}, fmt.Errorf("invalid value %q", rec[2])
+	}
+	pkg := PackageMetadata{Identifier: strings.TrimSpace(rec[0]), MassConstraint: mass, Valuation: value}
+	if len(rec) == 4 {
+		pkg.Category = strings.TrimSpace(rec[3])
+	}
+	return pkg, checkPackage(pkg)
+}
+
+// checkPackage applies the rules every catalog package must meet, whichever
+// format it was read from
+func checkPackage(pkg PackageMetadata) error {
+	switch {
+	case strings.TrimSpace(pkg.Identifier) == "":
+		return fmt.Errorf("empty identifier")
+	case pkg.MassConstraint < 0:
+		return fmt.Errorf("invalid mass %d", pkg.MassConstraint)
+	case pkg.Valuation < 0:
+		return fmt.Errorf("invalid value %d", pkg.Valuation)
+	case pkg.Cost < 0:
+		return fmt.Errorf("invalid cost %d", pkg.Cost)
+	}
+	return nil
+}
+
+// parseInlineCatalog parses "A:10:60,B:20:100" (identifier:mass:value[:category]) into packages
+func parseInlineCatalog(spec string) ([]PackageMetadata, error) {
+	var pkgs []PackageMetadata
+	for _, tok := range strings.Split(spec, ",") {
+		pkg, err := parseCatalogRow(strings.Split(strings.TrimSpace(tok), ":"))
+		if err != nil {
+			return nil, fmt.Errorf("inline package %q: %w", tok, err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
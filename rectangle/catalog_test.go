@@ -0,0 +1,49 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadCatalogSkipBadRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog string
+		want    []string
+	}{
+		{"mixed.csv", "identifier,mass,value\nA,10,60\nB,heavy,100\nC,30,120\n,5,5\nD,-5,80\nE,25,-1\nF,5,30,tools\nG,1\n", []string{"A", "C", "F"}},
+		{"mixed.json", `[{"Identifier":"A","MassConstraint":10,"Valuation":60},
+			{"Identifier":"B","MassConstraint":-5,"Valuation":100},
+			{"Identifier":"","MassConstraint":5,"Valuation":5},
+			{"Identifier":"C","MassConstraint":30,"Valuation":-120},
+			{"Identifier":"D","MassConstraint":15,"Valuation":80}]`, []string{"A", "D"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readCatalog(strings.NewReader(tt.catalog), tt.name, false, false); err == nil {
+				t.Error("invalid rows accepted without -skip-bad-rows")
+			}
+			pkgs, err := readCatalog(strings.NewReader(tt.catalog), tt.name, true, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := identifiers(pkgs); !slices.Equal(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCatalogValid(t *testing.T) {
+	pkgs, err := readCatalog(strings.NewReader("A,0,0\nB,20,100,tools\n"), "ok.csv", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PackageMetadata{{Identifier: "A"}, {Identifier: "B", MassConstraint: 20, Valuation: 100, Category: "tools"}}
+	if !slices.EqualFunc(pkgs, want, func(a, b PackageMetadata) bool {
+		return a.Identifier == b.Identifier && a.MassConstraint == b.MassConstraint && a.Valuation == b.Valuation && a.Category == b.Category
+	}) {
+		t.Errorf("readCatalog = %+v, want %+v", pkgs, want)
+	}
+}
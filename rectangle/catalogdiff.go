@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// catalogDiffSamples is the number of random emails -catalog-diff-impact optimizes
+const catalogDiffSamples = 100
+
+// CatalogDiffImpact summarizes how a base catalog update changes the optimum
+// over a sample of emails
+type CatalogDiffImpact struct {
+	Emails    int
+	Changed   int
+	ValueDiff int            // sum of new minus old optimum
+	Churn     map[string]int // emails where the package entered or left the selection
+}
+
+// MeasureCatalogDiffImpact optimizes each sampled email's packages generated
+// from the old and from the new base catalog and compares the selections
+func MeasureCatalogDiffImpact(oldBase, newBase []PackageMetadata, optimizer LoadOptimizer, ctx HeuristicContext, n int, rng *rand.Rand) CatalogDiffImpact {
+	before, after := NewEmailBasedPackageGenerator(), NewEmailBasedPackageGenerator()
+	before.basePackages, after.basePackages = oldBase, newBase
+	impact := CatalogDiffImpact{Emails: n, Churn: make(map[string]int)}
+	for i := 0; i < n; i++ {
+		email := randomEmail(rng)
+		oldSel := optimizer.Optimize(before.Generate(email), ctx)
+		newSel := optimizer.Optimize(after.Generate(email), ctx)
+		impact.ValueDiff += totalValue(newSel) - totalValue(oldSel)
+
+		in := make(map[string]bool, len(oldSel))
+		for _, p := range oldSel {
+			in[p.Identifier] = true
+		}
+		changed := false
+		for _, p := range newSel {
+			if in[p.Identifier] {
+				delete(in, p.Identifier)
+				continue
+			}
+			impact.Churn[p.Identifier]++
+			changed = true
+		}
+		for id := range in {
+			impact.Churn[id]++
+			changed = true
+		}
+		if changed {
+			impact.Changed++
+		}
+	}
+	return impact
+}
+
+// printCatalogDiffImpact writes the changed fraction, mean value change and
+// the packages that most often entered or left the optimum
+func printCatalogDiffImpact(w io.Writer, impact CatalogDiffImpact) {
+	fmt.Fprintf(w, "emails sampled:      %s\n", num(impact.Emails))
+	fmt.Fprintf(w, "optimum changed:     %s (%s%%)\n", num(impact.Changed), fnum(100*float64(impact.Changed)/float64(max(impact.Emails, 1)), 1))
+	fmt.Fprintf(w, "mean value change:   %s\n", fnum(float64(impact.ValueDiff)/float64(max(impact.Emails, 1)), 2))
+	if len(impact.Churn) == 0 {
+		fmt.Fprintln(w, "most responsible:    none")
+		return
+	}
+	ids := make([]string, 0, len(impact.Churn))
+	for id := range impact.Churn {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if impact.Churn[ids[i]] != impact.Churn[ids[j]] {
+			return impact.Churn[ids[i]] > impact.Churn[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	rows := make([]string, 0, 3)
+	for _, id := range ids[:min(3, len(ids))] {
+		rows = append(rows, fmt.Sprintf("%s (%s emails)", id, num(impact.Churn[id])))
+	}
+	fmt.Fprintf(w, "most responsible:    %s\n", strings.Join(rows, ", "))
+}
+
+// runCatalogDiffImpact loads the old,new base catalogs named by spec and
+// prints their impact on a random sample of emails
+func runCatalogDiffImpact(w io.Writer, opts *options) error {
+	paths := strings.Split(opts.catalogDiffImpact, ",")
+	if len(paths) != 2 {
+		return fmt.Errorf("-catalog-diff-impact wants old,new catalog files, got %q", opts.catalogDiffImpact)
+	}
+	oldBase, err := loadCatalog(paths[0], opts.skipBadRows, opts.strictJSON)
+	if err != nil {
+		return err
+	}
+	newBase, err := loadCatalog(paths[1], opts.skipBadRows, opts.strictJSON)
+	if err != nil {
+		return err
+	}
+	ctx := HeuristicContext{MaxLoad: opts.capacity, PriorityFactor: 1.0}
+	rng := rand.New(rand.NewSource(opts.randSeed))
+	printCatalogDiffImpact(w, MeasureCatalogDiffImpact(oldBase, newBase, optimizers[opts.algo](opts), ctx, catalogDiffSamples, rng))
+	return nil
+}
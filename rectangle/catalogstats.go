@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Summary holds descriptive statistics of one numeric package attribute
+type Summary struct {
+	Min, Max     int
+	Mean, StdDev float64 // population standard deviation
+	Q1, Median   float64
+	Q3           float64
+	Total        int
+}
+
+// CatalogStats summarizes the masses and values of a package set
+type CatalogStats struct {
+	Count int
+	Mass  Summary
+	Value Summary
+}
+
+// DescribePackages computes descriptive statistics for the catalog
+func DescribePackages(pkgs []PackageMetadata) CatalogStats {
+	masses := make([]int, len(pkgs))
+	values := make([]int, len(pkgs))
+	for i, p := range pkgs {
+		masses[i], values[i] = p.MassConstraint, p.Valuation
+	}
+	return CatalogStats{Count: len(pkgs), Mass: summarize(masses), Value: summarize(values)}
+}
+
+// summarize computes a Summary; quartiles interpolate linearly between ranks
+func summarize(xs []int) Summary {
+	if len(xs) == 0 {
+		return Summary{}
+	}
+	sorted := append([]int(nil), xs...)
+	sort.Ints(sorted)
+	s := Summary{Min: sorted[0], Max: sorted[len(sorted)-1]}
+	for _, x := range sorted {
+		s.Total += x
+	}
+	s.Mean = float64(s.Total) / float64(len(sorted))
+	for _, x := range sorted {
+		d := float64(x) - s.Mean
+		s.StdDev += d * d
+	}
+	s.StdDev = math.Sqrt(s.StdDev / float64(len(sorted)))
+	s.Q1, s.Median, s.Q3 = quantile(sorted, 0.25), quantile(sorted, 0.5), quantile(sorted, 0.75)
+	return s
+}
+
+// quantile returns the q-quantile of sorted values
+func quantile(sorted []int, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo+1 >= len(sorted) {
+		return float64(sorted[lo])
+	}
+	frac := pos - float64(lo)
+	return float64(sorted[lo]) + frac*float64(sorted[lo+1]-sorted[lo])
+}
+
+// printCatalogStats writes the catalog summary and the LP bound at the capacity
+func printCatalogStats(w io.Writer, pkgs []PackageMetadata, ctx HeuristicContext) {
+	stats := DescribePackages(pkgs)
+	fmt.Fprintf(w, "packages: %s\n", num(stats.Count))
+	fmt.Fprintf(w, "%-6s %6s %6s %8s %8s %8s %8s %8s %8s\n", "", "min", "max", "mean", "stddev", "q1", "median", "q3", "total")
+	for _, row := range []struct {
+		name string
+		s    Summary
+	}{{"mass", stats.Mass}, {"value", stats.Value}} {
+		fmt.Fprintf(w, "%-6s %6d %6d %8.2f %8.2f %8.2f %8.2f %8.2f %8d\n",
+			row.name, row.s.Min, row.s.Max, row.s.Mean, row.s.StdDev, row.s.Q1, row.s.Median, row.s.Q3, row.s.Total)
+	}
+	fmt.Fprintf(w, "infeasible packages: %s\n", num(len(infeasiblePackages(pkgs, ctx.MaxLoad))))
+	fmt.Fprintf(w, "LP bound at capacity %s: %s\n", num(ctx.MaxLoad), fnum(UpperBound(pkgs, ctx.MaxLoad), 2))
+}
+
+// infeasiblePackages returns the packages too heavy to load even alone
+func infeasiblePackages(pkgs []PackageMetadata, maxLoad int) []PackageMetadata {
+	var out []PackageMetadata
+	for _, p := range pkgs {
+		if p.MassConstraint > maxLoad {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// printInfeasiblePackages lists the packages that can never be selected
+func printInfeasiblePackages(w io.Writer, pkgs []PackageMetadata, maxLoad int) {
+	infeasible := infeasiblePackages(pkgs, maxLoad)
+	if len(infeasible) == 0 {
+		fmt.Fprintln(w, "infeasible packages: none")
+		return
+	}
+	for _, p := range infeasible {
+		fmt.Fprintf(w, "%s: Infeasible (weight %s > capacity %s)\n", p.Identifier, num(p.MassConstraint), num(maxLoad))
+	}
+}
+
+// worthlessPackages returns the packages whose value is zero or negative
+func worthlessPackages(pkgs []PackageMetadata) []PackageMetadata {
+	var out []PackageMetadata
+	for _, p := range pkgs {
+		if p.Valuation <= 0 {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// printZeroValueCount reports how many packages carry no value after preprocessing
+func printZeroValueCount(w io.Writer, pkgs []PackageMetadata) {
+	worthless := worthlessPackages(pkgs)
+	if len(worthless) == 0 {
+		fmt.Fprintln(w, "zero-value packages: none")
+		return
+	}
+	fmt.Fprintf(w, "zero-value packages: %s of %s: %s\n", num(len(worthless)), num(len(pkgs)), strings.Join(identifiers(worthless), ","))
+}
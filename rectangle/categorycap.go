@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// categoryCapFlag collects repeated -category-value-cap=category:max_value limits
+type categoryCapFlag map[string]int
+
+func (f categoryCapFlag) String() string {
+	specs := make([]string, 0, len(f))
+	for category, limit := range f {
+		specs = append(specs, fmt.Sprintf("%s:%d", category, limit))
+	}
+	sort.Strings(specs)
+	return strings.Join(specs, " ")
+}
+
+func (f categoryCapFlag) Set(s string) error {
+	i := strings.LastIndex(s, ":")
+	if i <= 0 {
+		return fmt.Errorf("category cap %q: want category:max_value", s)
+	}
+	limit, err := strconv.Atoi(s[i+1:])
+	if err != nil || limit < 0 {
+		return fmt.Errorf("category cap %q: invalid max value %q", s, s[i+1:])
+	}
+	f[s[:i]] = limit
+	return nil
+}
+
+// applyCategoryCaps limits how much value each capped category can
+// contribute: members keep their value in priority order until the
+// category's budget runs out, and lower-priority members are reduced to the
+// remainder (possibly zero). Since the budget covers every member, not only
+// selected ones, this is conservative when the optimum loads a subset.
+func applyCategoryCaps(pkgs []PackageMetadata, caps categoryCapFlag) []PackageMetadata {
+	out := make([]PackageMetadata, len(pkgs))
+	copy(out, pkgs)
+	members := make(map[string][]int)
+	for i, p := range out {
+		if _, ok := caps[p.Category]; ok && p.Category != "" {
+			members[p.Category] = append(members[p.Category], i)
+		}
+	}
+	for category, idx := range members {
+		sort.SliceStable(idx, func(a, b int) bool {
+			return computePriority(out[idx[a]], 1.0) > computePriority(out[idx[b]], 1.0)
+		})
+		remaining := caps[category]
+		for _, i := range idx {
+			if v := min(out[i].Valuation, remaining); v < out[i].Valuation {
+				verbosef("package %s: value %s reduced to %s by the %s category cap", out[i].Identifier, num(out[i].Valuation), num(v), category)
+				out[i].Valuation = v
+			}
+			remaining -= out[i].Valuation
+		}
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestCategoryCapForcesDiversity(t *testing.T) {
+	pkgs := []PackageMetadata{
+		{Identifier: "F1", MassConstraint: 5, Valuation: 100, Category: "food"},
+		{Identifier: "F2", MassConstraint: 5, Valuation: 90, Category: "food"},
+		{Identifier: "F3", MassConstraint: 5, Valuation: 80, Category: "food"},
+		{Identifier: "T1", MassConstraint: 5, Valuation: 40, Category: "tools"},
+	}
+	ctx := HeuristicContext{MaxLoad: 15}
+	if ids := identifiers((&PriorityBasedOptimizer{}).Optimize(pkgs, ctx)); slices.Contains(ids, "T1") {
+		t.Fatalf("uncapped: selected %v, want only food", ids)
+	}
+
+	// food may contribute 150: F1 keeps 100, F2 drops to 50 and F3 to 0
+	capped := applyCategoryCaps(pkgs, categoryCapFlag{"food": 150})
+	var values []int
+	for _, p := range capped {
+		values = append(values, p.Valuation)
+	}
+	if want := []int{100, 50, 0, 40}; !slices.Equal(values, want) {
+		t.Errorf("capped values %v, want %v", values, want)
+	}
+	if pkgs[1].Valuation != 90 {
+		t.Errorf("applyCategoryCaps modified its input")
+	}
+	ids := identifiers((&PriorityBasedOptimizer{}).Optimize(capped, ctx))
+	sort.Strings(ids)
+	if want := []string{"F1", "F2", "T1"}; !slices.Equal(ids, want) {
+		t.Errorf("capped: selected %v, want %v", ids, want)
+	}
+
+	// a cap on another category, or a generous one, changes nothing
+	for _, caps := range []categoryCapFlag{{"toys": 0}, {"food": 1000}} {
+		for i, p := range applyCategoryCaps(pkgs, caps) {
+			if p.Valuation != pkgs[i].Valuation {
+				t.Errorf("caps %v: %s value %d, want %d", caps, p.Identifier, p.Valuation, pkgs[i].Valuation)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// errChooseOneInfeasible is reported when the mandatory group picks cannot fit together
+var errChooseOneInfeasible = errors.New("no combination of one package per -choose-one group fits the capacity")
+
+// chooseOneFlag collects repeated -choose-one=A,B,C groups
+type chooseOneFlag [][]string
+
+func (f *chooseOneFlag) String() string {
+	groups := make([]string, len(*f))
+	for i, g := range *f {
+		groups[i] = strings.Join(g, ",")
+	}
+	return strings.Join(groups, " ")
+}
+
+func (f *chooseOneFlag) Set(s string) error {
+	var group []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			group = append(group, id)
+		}
+	}
+	if len(group) == 0 {
+		return fmt.Errorf("empty choose-one group %q", s)
+	}
+	for _, other := range *f {
+		for _, a := range other {
+			for _, b := range group {
+				if a == b {
+					return fmt.Errorf("package %s is in more than one choose-one group", a)
+				}
+			}
+		}
+	}
+	*f = append(*f, group)
+	return nil
+}
+
+// ChooseOneOptimizer solves the multiple-choice knapsack: exactly one package
+// from each group must be loaded, while packages outside every group stay
+// optional 0/1 items. Each group and each ungrouped package is one DP stage;
+// dp[s][w] is the best value after s stages within capacity w, unreachable
+// until every group so far has a pick. O(n·W) overall, since each package is
+// tried once per capacity in its stage.
+type ChooseOneOptimizer struct {
+	Groups [][]string
+}
+
+// Optimize returns the best selection honouring the groups, or nil when the
+// mandatory picks cannot fit (or a group has no package in the catalog)
+func (o *ChooseOneOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	const unreachable = math.MinInt
+	W := ctx.MaxLoad
+
+	groupOf := make(map[string]int)
+	for g, ids := range o.Groups {
+		for _, id := range ids {
+			groupOf[id] = g
+		}
+	}
+	// stages: the groups first, then each ungrouped package on its own
+	stages := make([][]PackageMetadata, len(o.Groups))
+	for _, p := range pkgs {
+		if g, ok := groupOf[p.Identifier]; ok {
+			stages[g] = append(stages[g], p)
+		} else {
+			stages = append(stages, []PackageMetadata{p})
+		}
+	}
+	mandatory := len(o.Groups)
+
+	dp := make([][]int, len(stages)+1)
+	choice := make([][]int, len(stages)+1) // index into the stage, -1 for none
+	dp[0] = make([]int, W+1)
+	for s, members := range stages {
+		dp[s+1] = make([]int, W+1)
+		choice[s+1] = make([]int, W+1)
+		for w := 0; w <= W; w++ {
+			best, pick := unreachable, -1
+			if s >= mandatory {
+				best = dp[s][w]
+			}
+			for k, p := range members {
+				if p.MassConstraint <= w && dp[s][w-p.MassConstraint] != unreachable {
+					if v := dp[s][w-p.MassConstraint] + p.Valuation; v > best {
+						best, pick = v, k
+					}
+				}
+			}
+			dp[s+1][w], choice[s+1][w] = best, pick
+		}
+	}
+	if dp[len(stages)][W] == unreachable {
+		return nil
+	}
+
+	res := []PackageMetadata{}
+	for s, w := len(stages), W; s > 0; s-- {
+		if k := choice[s][w]; k >= 0 {
+			p := stages[s-1][k]
+			res = append(res, p)
+			w -= p.MassConstraint
+		}
+	}
+	return res
+}
@@ -0,0 +1,1402 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// defaultMaxLoad is the truck capacity used by the challenge
+const defaultMaxLoad = 50
+
+// maxEmptyRetries caps how often -retry-on-empty-increment grows the capacity
+const maxEmptyRetries = 5
+
+// options holds the parsed command line configuration
+type options struct {
+	email             string
+	algo              string
+	failOnSuboptimal  int
+	leaveOneOut       bool
+	exportGraph       string
+	quiet             bool
+	verbose           bool
+	archive           string
+	showOptimalPath   bool
+	valueHistogram    bool
+	horizon           int
+	arrivals          map[string]int
+	dpLayout          string
+	format            string
+	includeStats      bool
+	rejectDegenerate  bool
+	seedCollisions    int
+	randSeed          int64
+	sweep             string
+	workers           int
+	batch             string
+	seedExport        string
+	compactOutput     bool
+	humanNumbers      bool
+	objective         string
+	traceBacktrack    bool
+	interactivePlot   bool
+	noProgress        bool
+	capacity          int
+	memoryLimit       int64
+	topK              int
+	whatIfCapacity    int
+	selfCheck         bool
+	explainBacktrack  bool
+	catalog           string
+	skipBadRows       bool
+	massParity        string
+	cacheDPTable      string
+	inlinePackages    string
+	jsonSchema        bool
+	secondBest        bool
+	strictJSON        bool
+	explainLP         bool
+	submitURL         string
+	submitRateLimit   float64
+	maxDistinctMass   int
+	upperBound        bool
+	retryIncrement    int
+	forceInclude      []string
+	domainWeight      uint64
+	jsonFile          string
+	policy            string
+	emitEvents        bool
+	robust            bool
+	robustObjective   string
+	candidates        []string
+	efficiency        bool
+	sandbox           bool
+	sandboxTimeout    time.Duration
+	sandboxChild      bool
+	yes               bool
+	minDensity        float64
+	exportNotebook    string
+	crossValidate     int
+	emitTestcase      bool
+	perturbation      int
+	perturbationRuns  int
+	topOff            bool
+	metricsFile       string
+	checkMonotone     bool
+	canonicalize      bool
+	targetValue       int
+	dryRun            bool
+	chooseOne         chooseOneFlag
+	failOnEmpty       bool
+	version           bool
+	reserve           int
+	priorityMatrix    bool
+	catalogStats      bool
+	compareSeeds      int
+	noAllocDP         bool
+	emitGoCode        string
+	explainGreedy     bool
+	groupBonus        groupFlag
+	protoBase64       bool
+	roundDivisor      int
+	persist           string
+	countHistory      bool
+	simulateChallenge bool
+	token             []byte
+	reverseBacktrack  bool
+	profileGenerate   int
+	fingerprint       bool
+	noSortOutput      bool
+	valueCap          int
+	weightFloor       int
+	flexPercent       float64
+	categoryCaps      categoryCapFlag
+	verboseJSON       bool
+	aliases           map[string]string
+	testCase          string
+	updateTestCase    bool
+	stdinPassword     bool
+	showInfeasible    bool
+	capacityProfile   bool
+	uniqueIDs         bool
+	allOptima         bool
+	lightestOptimum   bool
+	compactDP         bool
+	explainPriority   bool
+	checkOptimality   bool
+	randomizeIDs      int64
+	searchSpace       bool
+	itemContribution  bool
+	earlyTermination  bool
+	lookahead         int
+	persistImproved   bool
+	bestResult        string
+	explainImproved   bool
+	timeout           time.Duration
+	timeoutRetry      bool
+	validateResult    bool
+	nearOptimal       float64
+	circuitBreaker    bool
+	valueFloor        int
+	logMissing        bool
+	strict            bool
+	costBudget        int
+	exportPareto      string
+	warmup            int
+	strictTypes       bool
+	timeStamp         string
+	maxEmailLength    int
+	emitLatex         string
+	verifyOrder       bool
+	explainSA         bool
+	packageShuffle    int64
+	reportTiming      bool
+	generateN         int
+	checkBounds       bool
+	zeroValueCount    bool
+	gitCatalog        string
+	maxDPRows         int
+	checkSeedRange    bool
+	catalogDiffImpact string
+	noX               bool
+	noY               bool
+	onlyDynamic       bool
+	explainConflicts  bool
+	timeoutSignal     bool
+	traceAllocs       bool
+	unavailability    float64
+	benchmarkDP       bool
+	weightUtilization bool
+	enumerateNear     int
+	checkSymmetry     bool
+	countOptimal      bool
+	solverTimeoutMS   int
+	capSensitivity    bool
+	dpRowDiff         bool
+	packageSetHash    bool
+	resultHash        bool
+	resultSignKey     string
+	verifyResult      string
+}
+
+// standalone reports whether the requested mode runs without an email
+func (o *options) standalone() bool {
+	return o.countHistory || o.bestResult != "" || o.version || o.sandboxChild || o.seedCollisions > 0 || o.batch != "" || o.selfCheck || o.jsonSchema || o.catalog != "" || o.inlinePackages != "" || o.gitCatalog != "" || o.catalogDiffImpact != "" || o.verifyResult != ""
+}
+
+// seedEmail is the email as used for seeding, canonicalized if requested
+func (o *options) seedEmail(email string) string {
+	if o.canonicalize {
+		return canonicalEmail(email)
+	}
+	return email
+}
+
+// loadLimit is the capacity the optimizer may fill: -capacity less -reserve,
+// with -flex-percent of that added on top. nominal is the limit before flex.
+func (o *options) loadLimit() (limit, nominal int) {
+	nominal = o.capacity - o.reserve
+	limit = nominal
+	if o.flexPercent > 0 {
+		limit += int(float64(nominal) * o.flexPercent / 100)
+	}
+	return limit, nominal
+}
+
+// optimizers maps -algo names to optimizer constructors
+var optimizers = map[string]func(*options) LoadOptimizer{
+	"dp": func(opts *options) LoadOptimizer {
+		if opts.cacheDPTable != "" {
+			return &CachedDPOptimizer{Path: opts.cacheDPTable}
+		}
+		if opts.compactDP {
+			return &BitsetDP{}
+		}
+		return &PriorityBasedOptimizer{NewTable: dpLayouts[opts.dpLayout], NoAlloc: opts.noAllocDP, ForwardBacktrack: opts.reverseBacktrack}
+	},
+	"greedy": func(opts *options) LoadOptimizer { return &GreedyOptimizer{Lookahead: opts.lookahead} },
+	"sa":     func(opts *options) LoadOptimizer { return NewSimulatedAnnealingOptimizer(opts.randSeed) },
+	"lazy":   func(*options) LoadOptimizer { return &LazyDP{} },
+}
+
+// constrainedOptimizer returns the DP that replaces -algo for the flags in
+// options.constrainedOptimizers, or nil when none of them is set
+func constrainedOptimizer(opts *options) LoadOptimizer {
+	switch {
+	case opts.objective == "density":
+		return &DensityOptimizer{}
+	case opts.costBudget > 0:
+		return &BudgetAwareOptimizer{Budget: opts.costBudget}
+	case opts.lightestOptimum:
+		return &LightestOptimalOptimizer{}
+	case opts.roundDivisor > 0:
+		return &RoundWeightPreferenceOptimizer{Divisor: opts.roundDivisor}
+	case opts.targetValue > 0 && opts.earlyTermination:
+		return &EarlyTerminationDP{Target: opts.targetValue}
+	case opts.targetValue > 0:
+		return &MinWeightOptimizer{Target: opts.targetValue}
+	case len(opts.groupBonus) > 0:
+		return &GroupAwareBonusOptimizer{Groups: opts.groupBonus}
+	case len(opts.chooseOne) > 0:
+		return &ChooseOneOptimizer{Groups: opts.chooseOne}
+	case opts.maxDistinctMass > 0:
+		return &DistinctMassOptimizer{K: opts.maxDistinctMass}
+	case opts.massParity == "odd":
+		return &ParityOptimizer{Parity: 1}
+	case opts.massParity != "":
+		return &ParityOptimizer{}
+	}
+	return nil
+}
+
+// optimizerChain is the optimizer run and runBatch solve with: -algo, or
+// the constrained DP that replaces it, inside the -package-value-cap,
+// -randomize-identifiers, -force-include and -timeout wrappers. It keeps
+// the layers whose state is checked after optimizing.
+type optimizerChain struct {
+	LoadOptimizer // the outermost layer
+
+	solver   LoadOptimizer // the optimizer inside the wrappers
+	sandbox  *SandboxOptimizer
+	forced   *ForcedOptimizer
+	deadline *TimeoutOptimizer
+}
+
+// newOptimizerChain builds the optimizer the flags in opts ask for; the
+// caller wires up -timeout-signal, which needs a signal channel
+func newOptimizerChain(opts *options) *optimizerChain {
+	c := &optimizerChain{}
+	optimizer := optimizers[opts.algo](opts)
+	if opts.sandbox {
+		c.sandbox = &SandboxOptimizer{Algorithm: opts.algo, Solver: solverOptions(opts), Timeout: opts.sandboxTimeout}
+		optimizer = c.sandbox
+	}
+	if opts.circuitBreaker {
+		optimizer = NewCBOptimizer()
+	}
+	if constrained := constrainedOptimizer(opts); constrained != nil {
+		optimizer = constrained
+	}
+	c.solver = optimizer
+	if opts.valueCap > 0 {
+		optimizer = &ValueCapOptimizer{Inner: optimizer, Cap: opts.valueCap}
+	}
+	if opts.randomizeIDs != 0 {
+		optimizer = &RandomizedIDOptimizer{Inner: optimizer, Seed: opts.randomizeIDs}
+	}
+	if len(opts.forceInclude) > 0 {
+		c.forced = &ForcedOptimizer{Inner: optimizer, Forced: opts.forceInclude}
+		optimizer = c.forced
+	}
+	if opts.timeout > 0 {
+		c.deadline = &TimeoutOptimizer{Inner: optimizer, Timeout: opts.timeout}
+		if opts.timeoutRetry || opts.timeoutSignal {
+			c.deadline.Fallback = &GreedyOptimizer{}
+		}
+		optimizer = c.deadline
+	}
+	c.LoadOptimizer = optimizer
+	return c
+}
+
+// failure returns why the last Optimize call has no usable result, or nil
+func (c *optimizerChain) failure(selected []PackageMetadata) error {
+	switch {
+	case c.deadline != nil && c.deadline.TimedOut && c.deadline.Fallback == nil:
+		return errOptimizerTimeout
+	case c.sandbox != nil && c.sandbox.Err != nil:
+		return c.sandbox.Err
+	case c.forced != nil && c.forced.Err != nil:
+		return c.forced.Err
+	}
+	// the constrained solvers report infeasibility as a nil selection, which
+	// -force-include would otherwise hide behind the forced packages
+	found := selected
+	if c.forced != nil {
+		found = c.forced.Rest
+	}
+	if found != nil {
+		return nil
+	}
+	switch c.solver.(type) {
+	case *ParityOptimizer:
+		return errParityInfeasible
+	case *ChooseOneOptimizer:
+		return errChooseOneInfeasible
+	case *MinWeightOptimizer, *EarlyTerminationDP:
+		return errTargetUnreachable
+	}
+	return nil
+}
+
+// heuristicContext is the context every optimization runs in: the load
+// limit from loadLimit and a neutral priority factor. nominal is the limit
+// before -capacity-flex-percent.
+func (o *options) heuristicContext() (ctx HeuristicContext, nominal int) {
+	ctx.PriorityFactor = 1.0 // Neutral factor to avoid scaling issues
+	ctx.MaxLoad, nominal = o.loadLimit()
+	return ctx, nominal
+}
+
+// dpLayouts maps -dp-layout names to DP table allocators
+var dpLayouts = map[string]func(rows, cols int) DPTable{
+	"row":   newDenseTable,
+	"flat":  newFlatTable,
+	"delta": NewDeltaEncodedDP,
+}
+
+// checkEmailLength rejects emails over -max-email-length, which would make
+// seeding needlessly slow
+func (o *options) checkEmailLength(email string) error {
+	if o.maxEmailLength > 0 && len(email) > o.maxEmailLength {
+		return fmt.Errorf("email is %d bytes, longer than the %d byte limit", len(email), o.maxEmailLength)
+	}
+	return nil
+}
+
+// parseOptions parses flags followed by the email positional argument
+func parseOptions(args []string) (*options, error) {
+	opts := &options{}
+	fs := flag.NewFlagSet("optimizer", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: optimizer [flags] <email>")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.algo, "algo", "dp", "optimizer to run: dp, lazy (memoized top-down DP), greedy or sa (simulated annealing)")
+	fs.IntVar(&opts.failOnSuboptimal, "fail-on-suboptimal", -1, "exit 1 if the result is more than `margin` value units below the DP optimum (negative disables)")
+	fs.BoolVar(&opts.leaveOneOut, "leave-one-out", false, "print the optimum with each package removed in turn")
+	fs.StringVar(&opts.exportGraph, "export-graph", "", "write the dependency/conflict graph as Graphviz DOT to `path`")
+	fs.BoolVar(&opts.quiet, "quiet", false, "suppress warnings and diagnostics on stderr; errors still print (overrides -verbose)")
+	fs.BoolVar(&opts.verbose, "verbose", false, "print diagnostics to stderr")
+	fs.StringVar(&opts.archive, "archive", "", "write catalog, config and result as a zip to `path`")
+	fs.BoolVar(&opts.showOptimalPath, "show-optimal-path", false, "print the DP selection in the order items fill the truck")
+	fs.BoolVar(&opts.valueHistogram, "value-histogram", false, "print an ASCII histogram of the selected packages' values to stderr")
+	fs.IntVar(&opts.horizon, "horizon", 0, "plan loading over this many `days` with one truck per day")
+	fs.StringVar(&opts.dpLayout, "dp-layout", "row", "DP table storage: row, flat (single contiguous slice) or delta (varint deltas, for memory-constrained runs)")
+	fs.StringVar(&opts.format, "format", "text", "output format: text, json, msgpack, csv, table, markdown, proto or proto-text")
+	fs.BoolVar(&opts.includeStats, "include-stats", false, "add optimization statistics to JSON output")
+	fs.BoolVar(&opts.rejectDegenerate, "reject-degenerate", false, "fail if X or Y duplicates a base package's mass and value")
+	fs.IntVar(&opts.seedCollisions, "seed-collision-check", 0, "seed `N` random emails and report seed collisions")
+	fs.Int64Var(&opts.randSeed, "rand-seed", 1, "seed for the random number generator used by randomized modes")
+	fs.StringVar(&opts.sweep, "sweep", "", "optimize at every capacity in `from:to`")
+	fs.IntVar(&opts.workers, "workers", runtime.NumCPU(), "number of goroutines for -sweep")
+	fs.StringVar(&opts.batch, "batch", "", "optimize every email in `file` (one per line, - for stdin)")
+	fs.StringVar(&opts.seedExport, "seed-export", "", "write email, seed and dynamic package attributes as CSV to `file`")
+	fs.BoolVar(&opts.compactOutput, "compact-output", false, "print only the bare identifier list (minified JSON with -format json)")
+	fs.BoolVar(&opts.humanNumbers, "human-readable-numbers", false, "group digits with commas in reports, e.g. 1,000,000")
+	fs.StringVar(&opts.objective, "objective", "value", "what to maximize: value (total) or density (value per unit mass; may leave the truck underused)")
+	fs.BoolVar(&opts.traceBacktrack, "trace-backtrack", false, "trace the DP item recovery decisions to stderr")
+	fs.BoolVar(&opts.interactivePlot, "interactive-plot", false, "draw a live best-value plot on the terminal while -algo sa runs")
+	fs.BoolVar(&opts.noProgress, "no-progress", false, "disable progress output such as -interactive-plot")
+	fs.IntVar(&opts.capacity, "capacity", defaultMaxLoad, "truck capacity (MaxLoad)")
+	memoryLimit := fs.String("memory-limit", "", "refuse to run the DP if its table would exceed this `size` (e.g. 100MB)")
+	fs.IntVar(&opts.topK, "top-k", 0, "only consider the `K` highest-valued packages (0 = unlimited; may miss optima with light, low-value packages)")
+	fs.IntVar(&opts.whatIfCapacity, "what-if-capacity", 0, "compare the optimum at capacity and capacity+`delta`")
+	fs.BoolVar(&opts.selfCheck, "self-check", false, "health-check the DP optimizer and verify the binary's SHA-256 against the hash embedded at build time")
+	fs.BoolVar(&opts.explainBacktrack, "explain-backtrack", false, "print the comparison made at each DP backtracking step to stderr")
+	fs.StringVar(&opts.catalog, "catalog", "", "load packages from a .json or .csv `file` instead of generating them from the email")
+	fs.BoolVar(&opts.skipBadRows, "skip-bad-rows", false, "log and skip malformed catalog rows and invalid JSON packages instead of aborting")
+	fs.StringVar(&opts.massParity, "mass-parity", "", "require the total selected mass to be `even` or `odd`")
+	fs.StringVar(&opts.cacheDPTable, "cache-dp-table", "", "reuse DP tables stored in this gob `file`, keyed by package-list hash")
+	fs.StringVar(&opts.inlinePackages, "packages", "", "inline catalog `A:10:60,B:20:100` (identifier:mass:value) instead of generating from the email")
+	fs.BoolVar(&opts.jsonSchema, "json-schema", false, "print the JSON Schema (draft-07) for the JSON result and exit")
+	fs.BoolVar(&opts.secondBest, "second-best", false, "report the best selection distinct from the optimum and the gap")
+	fs.BoolVar(&opts.strictJSON, "strict-json", false, "reject JSON catalogs with trailing data after the array")
+	fs.BoolVar(&opts.explainLP, "explain-lp", false, "print the fractional LP relaxation tableau and rounding gap")
+	fs.StringVar(&opts.submitURL, "submit-url", "", "in -batch mode, POST each result to this `url`")
+	fs.Float64Var(&opts.submitRateLimit, "submit-rate-limit", 0, "throttle -submit-url to `N` requests per second (0 = unlimited)")
+	fs.IntVar(&opts.maxDistinctMass, "max-distinct-masses", 0, "use at most `K` distinct mass values among selected packages (0 = unlimited)")
+	fs.BoolVar(&opts.upperBound, "upper-bound", false, "print the LP relaxation upper bound on the optimal value")
+	fs.IntVar(&opts.retryIncrement, "retry-on-empty-increment", 0, "when nothing is selected, grow capacity by `N` and retry (up to 5 times)")
+	forceInclude := fs.String("force-include", "", "comma-separated package `IDs` that must be loaded")
+	fs.Uint64Var(&opts.domainWeight, "domain-weight", 1, "multiply the seed contribution of email domain runes by `N`")
+	fs.StringVar(&opts.jsonFile, "json-file", "", "also write the JSON result to `path`, keeping stdout in the selected format")
+	fs.StringVar(&opts.policy, "policy", "", "vet the result with the Rego policy in `path.rego` (package rectangle; deny rejects, selected replaces)")
+	fs.BoolVar(&opts.emitEvents, "emit-events", false, "publish CloudEvents JSON lines for each optimization stage to stdout")
+	fs.BoolVar(&opts.robust, "robust", false, "find one selection that performs best across all candidate emails given as arguments")
+	fs.StringVar(&opts.robustObjective, "robust-objective", "worst", "robust score: `worst` (maximize the minimum value) or average")
+	fs.BoolVar(&opts.efficiency, "efficiency", false, "report each selected package's value share divided by its mass share")
+	fs.BoolVar(&opts.sandbox, "sandbox", false, "run the optimizer in a child process so its panics or OOMs cannot crash this one")
+	fs.DurationVar(&opts.sandboxTimeout, "sandbox-timeout", 0, "kill the -sandbox child after this `duration` (0 = no limit)")
+	fs.BoolVar(&opts.sandboxChild, "sandbox-child", false, "internal: serve one -sandbox request on stdin/stdout")
+	fs.BoolVar(&opts.yes, "yes", false, "do not ask for confirmation before allocating a large DP table")
+	fs.Float64Var(&opts.minDensity, "min-density", 0, "drop packages with value/mass below `R` before optimizing (may miss the unfiltered optimum)")
+	fs.StringVar(&opts.exportNotebook, "export-notebook", "", "write the problem as a Jupyter notebook (pandas, matplotlib, LaTeX, PuLP) to `path`")
+	fs.IntVar(&opts.crossValidate, "cross-validate", 0, "optimize with each of `N` random folds held out and report consistency")
+	fs.BoolVar(&opts.emitTestcase, "emit-testcase", false, "print the catalog, capacity and result as a Go table-driven test entry")
+	fs.IntVar(&opts.perturbation, "weight-perturbation", 0, "re-optimize with uniform noise in [-`delta`, +delta] added to each weight and report robustness")
+	fs.IntVar(&opts.perturbationRuns, "perturbation-runs", 100, "number of -weight-perturbation runs")
+	fs.BoolVar(&opts.topOff, "top-off", false, "report the value of filling leftover capacity with a fraction of the densest unselected package")
+	fs.StringVar(&opts.metricsFile, "metrics-file", "", "in -batch mode, write aggregate metrics in Prometheus text format to `path`")
+	// on by default under go test, so that every test run checks the fill
+	fs.BoolVar(&opts.checkMonotone, "check-monotonicity", testing.Testing(), "verify the DP's best value never decreases as capacity grows")
+	fs.BoolVar(&opts.canonicalize, "canonicalize-email", false, "normalize emails per provider (lowercase; Gmail dots and +tags, etc.) before seeding")
+	fs.IntVar(&opts.targetValue, "target-value", 0, "instead of maximizing value, find the lightest selection worth at least `T`")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "validate flags and load the catalog, then exit without optimizing")
+	fs.Var(&opts.chooseOne, "choose-one", "load exactly one package from the comma-separated `group` (repeatable; other packages stay optional)")
+	fs.BoolVar(&opts.failOnEmpty, "fail-on-zero-capacity-utilization", false, "exit 1 when the optimal selection is empty")
+	fs.BoolVar(&opts.version, "version", false, "print the build version, commit and package generator version and exit")
+	fs.IntVar(&opts.reserve, "reserve", 0, "keep `N` units of capacity free while optimizing; utilization is still reported against the full capacity")
+	fs.BoolVar(&opts.priorityMatrix, "priority-matrix", false, "print the pairwise matrix of value gained by having both packages versus the better one")
+	fs.BoolVar(&opts.catalogStats, "catalog-stats", false, "print descriptive statistics of the package masses and values")
+	fs.IntVar(&opts.compareSeeds, "compare-seeds", 0, "re-optimize with `count` salted variants of the email's seed and report how often the selection changes")
+	fs.BoolVar(&opts.noAllocDP, "no-allocation-dp", false, "solve instances of up to 8 packages at capacity <= 50 in a stack array")
+	fs.StringVar(&opts.emitGoCode, "emit-go-code", "", "write a standalone Go program solving this instance to `path`")
+	fs.BoolVar(&opts.explainGreedy, "explain-greedy", false, "with -algo greedy, print each package's ratio and selection decision to stderr")
+	fs.Var(&opts.groupBonus, "group-bonus", "award a bonus when every package of a group is loaded, as `ID:bonus:A,B` (repeatable)")
+	fs.BoolVar(&opts.protoBase64, "proto-base64", false, "base64-encode -format proto output for text channels")
+	fs.IntVar(&opts.roundDivisor, "round-divisor", 0, "among equally valuable selections prefer packages whose mass is a multiple of `N`")
+	fs.StringVar(&opts.persist, "persist", "", "append each result to the JSON Lines results database at `path`")
+	fs.BoolVar(&opts.countHistory, "package-count-history", false, "plot how often each package was selected over time in the -persist database")
+	fs.BoolVar(&opts.simulateChallenge, "simulate-challenge", false, "optimize the email and POST the answer to -submit-url with the bearer token: args <email> <token>")
+	fs.BoolVar(&opts.reverseBacktrack, "reverse-backtrack", false, "recover DP items from first to last; may pick a different set among equally optimal ones")
+	fs.IntVar(&opts.profileGenerate, "profile-generate", 0, "time `N` package generations and DP runs for the email and print per-call statistics")
+	fs.BoolVar(&opts.fingerprint, "catalog-fingerprint", false, "print an order-independent SHA-256 of the package catalog")
+	fs.BoolVar(&opts.noSortOutput, "no-sort-output", false, "list selected packages in the optimizer's order (DP backtracking: last item first) instead of alphabetically")
+	fs.IntVar(&opts.valueCap, "package-value-cap", 0, "optimize with every package value clamped to at most `N`; output reports the original values")
+	fs.IntVar(&opts.weightFloor, "package-weight-floor", 0, "raise every package mass below `N` to N before optimizing")
+	fs.Float64Var(&opts.flexPercent, "capacity-flex-percent", 0, "let the optimizer overload the capacity by up to `P` percent, warning when it does")
+	opts.categoryCaps = categoryCapFlag{}
+	fs.Var(opts.categoryCaps, "category-value-cap", "limit the total value packages of a catalog `category:max_value` may contribute (repeatable)")
+	fs.BoolVar(&opts.verboseJSON, "verbose-json", false, "write JSON to stdout and verbose diagnostics plus a result table to stderr")
+	aliases := fs.String("package-alias", "", "rename packages in the output only, as `A:Alpha,B:Beta`")
+	fs.StringVar(&opts.testCase, "generate-test-case", "", "write the catalog and optimal result as the golden fixture testdata/`name`.json")
+	fs.BoolVar(&opts.updateTestCase, "update-test-case", false, "allow -generate-test-case to overwrite an existing fixture")
+	fs.BoolVar(&opts.stdinPassword, "stdin-password", false, "read the -simulate-challenge token from stdin (echo off on a terminal) instead of an argument")
+	fs.BoolVar(&opts.showInfeasible, "show-infeasible-packages", false, "list packages heavier than the capacity on stderr before the result")
+	fs.BoolVar(&opts.capacityProfile, "capacity-profile", false, "print the DP optimum at every 10% step of the capacity, from one DP table")
+	fs.BoolVar(&opts.uniqueIDs, "catalog-validate-uniqueness", false, "fail if two packages share an identifier")
+	fs.BoolVar(&opts.allOptima, "compute-all-optima", false, fmt.Sprintf("list every optimal DP selection (at most %d)", maxAllOptima))
+	fs.BoolVar(&opts.lightestOptimum, "weight-class-priority", false, "among equally valuable selections prefer the one with the least total mass")
+	fs.BoolVar(&opts.compactDP, "compact-dp", false, "solve -algo dp over math/big bitsets of reachable (value, mass) pairs")
+	fs.BoolVar(&opts.explainPriority, "explain-priority", false, "print each term of every package's priority score to stderr")
+	fs.BoolVar(&opts.checkOptimality, "check-optimality-conditions", false, "test the selection against sufficient 0/1 optimality conditions and print violations to stderr")
+	fs.Int64Var(&opts.randomizeIDs, "randomize-identifiers", 0, "hide package identifiers from the optimizer behind random strings drawn from `seed` (0 = off)")
+	fs.BoolVar(&opts.searchSpace, "search-space-size", false, "count the package subsets that fit the capacity")
+	fs.BoolVar(&opts.itemContribution, "item-contribution", false, "print each selected package's marginal value (optimum minus optimum without it) to stderr")
+	fs.BoolVar(&opts.earlyTermination, "early-termination", false, "with -target-value, stop the DP at the first row reaching the target instead of minimizing mass")
+	fs.IntVar(&opts.lookahead, "weight-lookahead", 0, "with -algo greedy, skip a package when the next `N` packages are worth more without it")
+	fs.BoolVar(&opts.persistImproved, "persist-on-improvement", false, "with -persist, only store results worth more than every stored result for the email")
+	fs.StringVar(&opts.bestResult, "best-result", "", "print the all-time best result stored in -persist for `email` and exit")
+	fs.BoolVar(&opts.explainImproved, "explain-improvements", false, "print how the DP optimum grows as items 1..k are made available, to stderr")
+	fs.DurationVar(&opts.timeout, "timeout", 0, "give up on the optimizer after this `duration` (0 = no limit)")
+	fs.BoolVar(&opts.timeoutRetry, "timeout-retry", false, "when -timeout expires, return the greedy selection marked approximate instead of failing")
+	fs.BoolVar(&opts.validateResult, "validate-after-optimization", false, "check the result against the catalog (known IDs, totals, capacity) and fail if it is invalid")
+	fs.Float64Var(&opts.nearOptimal, "show-near-optimal", 0, fmt.Sprintf("list the %d best selections worth at least (100-`percent`)%% of the optimum", nearOptimalLimit))
+	fs.BoolVar(&opts.circuitBreaker, "circuit-breaker", false, "in -batch mode, answer with greedy for 30s once more than 3 of the last 10 DP runs took over 1s")
+	fs.IntVar(&opts.valueFloor, "package-value-floor", 0, "raise every package value below `N` to N before optimizing")
+	fs.BoolVar(&opts.logMissing, "log-missing-packages", false, "warn about -force-include, -choose-one and -group-bonus IDs missing from the catalog and ignore them")
+	fs.BoolVar(&opts.strict, "strict", false, "with -log-missing-packages, fail on missing IDs instead of warning")
+	fs.IntVar(&opts.costBudget, "total-cost-constraint", 0, "also keep the total package cost within `N` (2-D knapsack; 0 = no budget)")
+	fs.StringVar(&opts.exportPareto, "export-pareto-front", "", "write every capacity where the optimum improves, with its mass, value and selection, as CSV to `path`")
+	fs.IntVar(&opts.warmup, "warmup", 0, "optimize `N` random catalogs before the real run to pre-fault memory and warm caches")
+	fs.BoolVar(&opts.strictTypes, "strict-types", false, "reject identifiers not matching [A-Z][A-Z0-9]* and masses or values outside [1, 1000000) and [1, 10000000)")
+	fs.StringVar(&opts.timeStamp, "time-stamp", "rfc3339", "prefix diagnostic lines with a timestamp: rfc3339 (seconds), ms, us, ns or none")
+	fs.IntVar(&opts.maxEmailLength, "max-email-length", 254, "reject emails longer than `N` bytes before seeding (0 disables)")
+	fs.StringVar(&opts.emitLatex, "emit-latex", "", "write the packages as a LaTeX tabular with selected rows shaded to `path`")
+	fs.BoolVar(&opts.verifyOrder, "verify-catalog-order", false, "panic if the generated base packages are not A..F in order (developer check)")
+	fs.BoolVar(&opts.explainSA, "explain-sa", false, "log every simulated annealing move and an acceptance probability histogram to stderr (-algo sa)")
+	fs.Int64Var(&opts.packageShuffle, "package-shuffle", 0, "re-optimize with the packages permuted by `seed` and fail if the value changes")
+	fs.BoolVar(&opts.reportTiming, "report-timing-breakdown", false, "print wall time per phase (generation, validation, optimization, output) to stderr; implied by -verbose")
+	fs.IntVar(&opts.generateN, "generate-n", 0, "append `N` synthetic packages seeded from the email, with masses up to -capacity and values up to 10x it")
+	fs.BoolVar(&opts.checkBounds, "check-lb-ub", false, "verify greedy value <= DP optimum <= LP relaxation bound")
+	fs.BoolVar(&opts.zeroValueCount, "zero-value-count", false, "report packages left with zero or negative value after preprocessing on stderr")
+	fs.StringVar(&opts.gitCatalog, "catalog-from-git", "", "load packages from `repo:path:ref` (local repository or clone URL; clones are cached and refetched unless ref is a full commit hash)")
+	fs.IntVar(&opts.maxDPRows, "max-dp-rows", 0, "optimize over only the first `N` packages, warning about the rest (0 = no limit)")
+	// on by default under go test, so that every test run checks the generator
+	fs.BoolVar(&opts.checkSeedRange, "check-seed-range", testing.Testing(), "fail if the generated X and Y attributes fall outside their documented ranges")
+	fs.StringVar(&opts.catalogDiffImpact, "catalog-diff-impact", "", "compare optima for 100 random emails with base packages from the `old,new` catalog files and exit")
+	fs.BoolVar(&opts.noX, "no-x-package", false, "leave the dynamic X package out of the generated catalog")
+	fs.BoolVar(&opts.noY, "no-y-package", false, "leave the dynamic Y package out of the generated catalog")
+	fs.BoolVar(&opts.onlyDynamic, "only-dynamic", false, "leave the base packages A-F out of the generated catalog")
+	fs.BoolVar(&opts.explainConflicts, "explain-conflicts", false, "show which ExcludedBy pairs the greedy solution wants together and how dropping one resolves each, on stderr")
+	fs.BoolVar(&opts.timeoutSignal, "timeout-signal", false, "with -timeout, return the greedy selection marked approximate as soon as SIGUSR1 arrives")
+	fs.BoolVar(&opts.traceAllocs, "trace-allocations", false, "print net heap allocations and bytes allocated per phase to stderr")
+	fs.Float64Var(&opts.unavailability, "catalog-simulate-unavailability", 0, "re-optimize 10 times with each package out of stock with probability `rate` and report the value lost")
+	fs.BoolVar(&opts.benchmarkDP, "benchmark-against-dp", false, "also solve exactly with the DP and print the heuristic's value gap to stderr (non-dp -algo)")
+	fs.BoolVar(&opts.weightUtilization, "show-weight-utilization-per-item", false, "print each selected package's share of the selected mass to stderr")
+	fs.IntVar(&opts.enumerateNear, "enumerate-near-optimal", -1, fmt.Sprintf("list every selection worth at least the optimum minus `tolerance` (at most %d) and exit (negative disables)", maxNearOptimal))
+	fs.BoolVar(&opts.checkSymmetry, "check-dp-symmetry", false, "verify swapping each package with an identical duplicate leaves the DP table unchanged")
+	fs.BoolVar(&opts.countOptimal, "count-optimal-solutions", false, "count the distinct optimal selections; printed to stderr and included in JSON output")
+	fs.IntVar(&opts.solverTimeoutMS, "solver-timeout-ms", 0, "like -timeout, in integer milliseconds `N` (0 = no limit)")
+	fs.BoolVar(&opts.capSensitivity, "capacity-sensitivity-report", false, "list every capacity where the optimum rises, with the packages added and the value jump, and exit")
+	fs.BoolVar(&opts.dpRowDiff, "show-dp-row-diff", false, "print, for each DP row, the capacities whose value changed from the row above to stderr")
+	fs.BoolVar(&opts.packageSetHash, "package-set-hash", false, "print the first 8 hex characters of the catalog fingerprint and exit")
+	fs.BoolVar(&opts.resultHash, "result-hash", false, "append |HMAC-SHA256 of the sorted identifiers, keyed with -result-sign-key, to the text result")
+	fs.StringVar(&opts.resultSignKey, "result-sign-key", "", "secret `key` for -result-hash and -verify-result-hash")
+	fs.StringVar(&opts.verifyResult, "verify-result-hash", "", "check a `signed` A,B,C|hex result against -result-sign-key and exit")
+	arrivals := fs.String("arrivals", "", "per-package arrival days for -horizon, e.g. `A:1,X:2` (default day 1)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if opts.arrivals, err = parseArrivals(*arrivals); err != nil {
+		return nil, err
+	}
+	if *memoryLimit != "" {
+		if opts.memoryLimit, err = parseByteSize(*memoryLimit); err != nil {
+			return nil, err
+		}
+	}
+	opts.forceInclude = parseForceInclude(*forceInclude)
+	if opts.aliases, err = parsePackageAliases(*aliases); err != nil {
+		return nil, err
+	}
+	if opts.capacity < 0 {
+		return nil, fmt.Errorf("capacity must be non-negative")
+	}
+	if opts.perturbationRuns < 1 {
+		return nil, fmt.Errorf("perturbation-runs must be positive")
+	}
+	if opts.retryIncrement < 0 {
+		return nil, fmt.Errorf("retry-on-empty-increment must be non-negative")
+	}
+	if opts.reserve < 0 || opts.reserve > 0 && opts.reserve >= opts.capacity {
+		return nil, fmt.Errorf("reserve must be non-negative and less than the capacity %d", opts.capacity)
+	}
+	if opts.flexPercent < 0 || opts.flexPercent > 100 {
+		return nil, fmt.Errorf("capacity-flex-percent must be between 0 and 100")
+	}
+	if limit, _ := opts.loadLimit(); limit+opts.whatIfCapacity < 0 {
+		return nil, fmt.Errorf("what-if-capacity %d takes the capacity %d below zero", opts.whatIfCapacity, limit)
+	}
+	if opts.compareSeeds < 0 || opts.compareSeeds > len(compareSeedSalts) {
+		return nil, fmt.Errorf("compare-seeds must be between 0 and %d", len(compareSeedSalts))
+	}
+	if opts.roundDivisor < 0 {
+		return nil, fmt.Errorf("round-divisor must be non-negative")
+	}
+	if opts.nearOptimal < 0 || opts.nearOptimal > 100 {
+		return nil, fmt.Errorf("show-near-optimal must be a percentage between 0 and 100")
+	}
+	if opts.costBudget < 0 {
+		return nil, fmt.Errorf("total-cost-constraint must be non-negative")
+	}
+	if opts.lookahead < 0 {
+		return nil, fmt.Errorf("weight-lookahead must be non-negative")
+	}
+	if opts.maxDistinctMass < 0 {
+		return nil, fmt.Errorf("max-distinct-masses must be non-negative")
+	}
+	if opts.unavailability < 0 || opts.unavailability > 1 {
+		return nil, fmt.Errorf("catalog-simulate-unavailability must be between 0 and 1")
+	}
+	if opts.solverTimeoutMS < 0 {
+		return nil, fmt.Errorf("solver-timeout-ms must be non-negative")
+	}
+	if opts.maxDPRows < 0 {
+		return nil, fmt.Errorf("max-dp-rows must be non-negative")
+	}
+	if opts.generateN < 0 {
+		return nil, fmt.Errorf("generate-n must be non-negative")
+	}
+	if opts.maxEmailLength < 0 {
+		return nil, fmt.Errorf("max-email-length must be non-negative")
+	}
+	if opts.topK < 0 {
+		return nil, fmt.Errorf("top-k must be non-negative")
+	}
+
+	if opts.verboseJSON {
+		opts.verbose = true
+		if opts.format == "text" {
+			opts.format = "json"
+		}
+	}
+	humanNumbers = opts.humanNumbers
+	if err := setDiagTimestamp(opts.timeStamp); err != nil {
+		return nil, err
+	}
+	switch {
+	case opts.quiet:
+		setDiagLevel(levelQuiet)
+	case opts.verbose:
+		setDiagLevel(levelVerbose)
+	default:
+		setDiagLevel(levelNormal)
+	}
+
+	if _, ok := optimizers[opts.algo]; !ok {
+		return nil, fmt.Errorf("unknown optimizer %q", opts.algo)
+	}
+	if _, ok := formatters[opts.format]; !ok {
+		return nil, fmt.Errorf("unknown format %q (want %s)", opts.format, strings.Join(formatters.Names(), ", "))
+	}
+	if opts.objective != "value" && opts.objective != "density" {
+		return nil, fmt.Errorf("unknown objective %q", opts.objective)
+	}
+	if opts.massParity != "" && opts.massParity != "even" && opts.massParity != "odd" {
+		return nil, fmt.Errorf("invalid mass parity %q: want even or odd", opts.massParity)
+	}
+	if _, ok := dpLayouts[opts.dpLayout]; !ok {
+		return nil, fmt.Errorf("unknown DP layout %q", opts.dpLayout)
+	}
+	if opts.countHistory && opts.persist == "" {
+		return nil, fmt.Errorf("-package-count-history requires -persist")
+	}
+	if (opts.persistImproved || opts.bestResult != "") && opts.persist == "" {
+		return nil, fmt.Errorf("-persist-on-improvement and -best-result require -persist")
+	}
+	if err := opts.conflicts(); err != nil {
+		return nil, err
+	}
+	if opts.solverTimeoutMS > 0 {
+		opts.timeout = time.Duration(opts.solverTimeoutMS) * time.Millisecond
+	}
+	if opts.robustObjective != "worst" && opts.robustObjective != "average" {
+		return nil, fmt.Errorf("unknown robust objective %q", opts.robustObjective)
+	}
+	if opts.simulateChallenge {
+		switch {
+		case opts.stdinPassword && fs.NArg() != 1:
+			return nil, fmt.Errorf("-simulate-challenge -stdin-password needs <email>")
+		case !opts.stdinPassword && fs.NArg() != 2:
+			return nil, fmt.Errorf("-simulate-challenge needs <email> <token>")
+		case !opts.stdinPassword:
+			opts.token = []byte(fs.Arg(1))
+		}
+	}
+	if opts.robust {
+		if fs.NArg() < 1 {
+			return nil, fmt.Errorf("-robust needs at least one candidate email")
+		}
+		for _, email := range fs.Args() {
+			if err := opts.checkEmailLength(email); err != nil {
+				return nil, err
+			}
+			opts.candidates = append(opts.candidates, opts.seedEmail(email))
+		}
+	}
+	if err := opts.checkEmailLength(fs.Arg(0)); err != nil {
+		return nil, err
+	}
+	if opts.standalone() {
+		opts.email = opts.seedEmail(fs.Arg(0))
+		return opts, nil
+	}
+	if fs.NArg() < 1 {
+		return nil, fmt.Errorf("Missing configuration parameter")
+	}
+	opts.email = opts.seedEmail(fs.Arg(0))
+	if len(opts.email) == 0 {
+		return nil, fmt.Errorf("Configuration cannot be empty")
+	}
+	return opts, nil
+}
+
+// run executes the optimizer for the given arguments and returns the exit code
+func run(args []string) int {
+	opts, err := parseOptions(args)
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if opts.version {
+		printVersion(os.Stdout)
+		return 0
+	}
+
+	if opts.stdinPassword {
+		if opts.token, err = readPassword(); err != nil {
+			fmt.Fprintln(os.Stderr, "stdin password:", err)
+			return 1
+		}
+	}
+	// the token lives only in this buffer; wipe it however run returns
+	defer clear(opts.token)
+
+	if opts.dryRun && !opts.simulateChallenge {
+		return dryRun(opts)
+	}
+
+	if opts.sandboxChild {
+		return runSandboxChild(opts)
+	}
+
+	if opts.selfCheck {
+		return runSelfCheck()
+	}
+
+	if opts.countHistory {
+		records, err := readResults(opts.persist)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "persist:", err)
+			return 1
+		}
+		printPackageCountHistory(os.Stdout, records)
+		return 0
+	}
+
+	if opts.bestResult != "" {
+		records, err := readResults(opts.persist)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "persist:", err)
+			return 1
+		}
+		best, ok := bestStored(records, emailHash(opts.seedEmail(opts.bestResult)))
+		if !ok {
+			fmt.Fprintf(os.Stderr, "no stored result for %s\n", opts.bestResult)
+			return 1
+		}
+		infof("stored %s", best.Time.Format(time.RFC3339))
+		fmt.Print(JSONFormatter{Compact: opts.compactOutput}.Format(best.Result))
+		return 0
+	}
+
+	if opts.jsonSchema {
+		fmt.Print(optimizationResultSchema)
+		return 0
+	}
+
+	if opts.seedCollisions > 0 {
+		printSeedCollisions(os.Stdout, opts.seedCollisions, rand.New(rand.NewSource(opts.randSeed)))
+		return 0
+	}
+
+	if opts.verifyResult != "" {
+		if err := verifyResultHash(opts.verifyResult, []byte(opts.resultSignKey)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println("signature valid")
+		return 0
+	}
+
+	if opts.catalogDiffImpact != "" {
+		if err := runCatalogDiffImpact(os.Stdout, opts); err != nil {
+			fmt.Fprintln(os.Stderr, "catalog diff impact:", err)
+			return 1
+		}
+		return 0
+	}
+
+	if opts.batch != "" {
+		return runBatch(opts)
+	}
+
+	if opts.robust {
+		return runRobust(opts)
+	}
+
+	if opts.profileGenerate > 0 {
+		profileGenerate(os.Stdout, opts, opts.profileGenerate)
+		return 0
+	}
+
+	timer := newPhaseTimer(opts.traceAllocs)
+	packages, err := loadPackages(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	timer.mark("package generation")
+	if packages, err = preprocess(opts, packages); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	timer.mark("validation")
+	var policy *Policy
+	if opts.policy != "" {
+		if policy, err = loadPolicy(opts.policy); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	// Configure optimizer with heuristic context
+	chain := newOptimizerChain(opts)
+	optimizer, solver, deadline := LoadOptimizer(chain), chain.solver, chain.deadline
+	if deadline != nil && opts.timeoutSignal {
+		if len(resultSignals) == 0 {
+			warnf("-timeout-signal is not supported on this platform")
+		} else {
+			sig := make(chan os.Signal, 1)
+			signal.Notify(sig, resultSignals...)
+			defer signal.Stop(sig)
+			deadline.Signal = sig
+		}
+	}
+	// nominal is the load limit before any flex; selections above it are overloads
+	ctx, nominal := opts.heuristicContext()
+	if opts.flexPercent > 0 {
+		verbosef("flex capacity: %s (nominal %s)", num(ctx.MaxLoad), num(nominal))
+	}
+
+	for _, w := range LintCatalog(packages) {
+		verbosef("lint: %s", w)
+	}
+	for _, pkg := range packages {
+		verbosef("package %s: mass=%s value=%s", pkg.Identifier, num(pkg.MassConstraint), num(pkg.Valuation))
+		if pkg.MassConstraint > ctx.MaxLoad {
+			warnf("package %s (mass %s) exceeds capacity %s and can never be loaded", pkg.Identifier, num(pkg.MassConstraint), num(ctx.MaxLoad))
+		}
+	}
+
+	if opts.showInfeasible {
+		printInfeasiblePackages(diagOut, packages, ctx.MaxLoad)
+	}
+	if opts.zeroValueCount {
+		printZeroValueCount(diagOut, packages)
+	}
+
+	if opts.seedExport != "" {
+		if err := exportSeeds(opts.seedExport, []string{opts.email}, opts.domainWeight); err != nil {
+			fmt.Fprintln(os.Stderr, "seed export:", err)
+			return 1
+		}
+	}
+
+	if opts.exportGraph != "" {
+		if err := exportGraph(opts.exportGraph, packages); err != nil {
+			fmt.Fprintln(os.Stderr, "export graph:", err)
+			return 1
+		}
+	}
+	if opts.explainConflicts {
+		printExplainConflicts(diagOut, packages, ctx)
+	}
+
+	if opts.exportPareto != "" {
+		if err := exportParetoFront(opts.exportPareto, packages, ctx.MaxLoad); err != nil {
+			fmt.Fprintln(os.Stderr, "export pareto front:", err)
+			return 1
+		}
+	}
+
+	if opts.emitGoCode != "" {
+		if err := writeGoProgram(opts.emitGoCode, opts.email, packages, ctx); err != nil {
+			fmt.Fprintln(os.Stderr, "emit go code:", err)
+			return 1
+		}
+	}
+
+	if opts.exportNotebook != "" {
+		if err := exportNotebook(opts.exportNotebook, packages, ctx, opts.email); err != nil {
+			fmt.Fprintln(os.Stderr, "export notebook:", err)
+			return 1
+		}
+	}
+
+	if opts.horizon > 0 {
+		printHorizon(os.Stdout, PlanHorizon(packages, opts.arrivals, opts.horizon, optimizer, ctx))
+		return 0
+	}
+
+	if opts.perturbation > 0 {
+		rng := rand.New(rand.NewSource(opts.randSeed))
+		printPerturbation(os.Stdout, PerturbWeights(packages, opts.perturbation, opts.perturbationRuns, optimizer, ctx, rng))
+		return 0
+	}
+
+	if opts.unavailability > 0 {
+		rng := rand.New(rand.NewSource(opts.randSeed))
+		printUnavailability(os.Stdout, SimulateUnavailability(packages, opts.unavailability, unavailabilityRuns, optimizer, ctx, rng))
+		return 0
+	}
+
+	if opts.crossValidate > 0 {
+		if opts.crossValidate < 2 || opts.crossValidate > len(packages) {
+			fmt.Fprintf(os.Stderr, "cross-validate needs between 2 and %d folds\n", len(packages))
+			return 1
+		}
+		rng := rand.New(rand.NewSource(opts.randSeed))
+		printCrossValidation(os.Stdout, CrossValidate(packages, opts.crossValidate, optimizer, ctx, rng))
+		return 0
+	}
+
+	if opts.sweep != "" {
+		from, to, err := parseSweep(opts.sweep)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		newOptimizer := func() LoadOptimizer { return optimizers[opts.algo](opts) }
+		if cached, ok := optimizers[opts.algo](opts).(*CachedDPOptimizer); ok {
+			// the workers share one cache rather than each rewriting the file
+			newOptimizer = func() LoadOptimizer { return cached }
+		}
+		printSweep(os.Stdout, SweepCapacities(packages, from, to, opts.workers, newOptimizer, ctx.PriorityFactor))
+		return 0
+	}
+
+	if opts.capacityProfile {
+		printCapacityProfile(os.Stdout, CapacityProfile(packages, ctx.MaxLoad))
+		return 0
+	}
+
+	if opts.capSensitivity {
+		printCapacitySensitivity(os.Stdout, packages, ctx.MaxLoad)
+		return 0
+	}
+
+	if opts.whatIfCapacity != 0 {
+		printWhatIfCapacity(os.Stdout, packages, ctx, opts.whatIfCapacity)
+		return 0
+	}
+
+	if opts.upperBound {
+		fmt.Printf("upper bound: %s\n", fnum(UpperBound(packages, ctx.MaxLoad), 2))
+		return 0
+	}
+
+	if opts.explainLP {
+		printLPTableau(os.Stdout, packages, ctx)
+		return 0
+	}
+
+	if opts.enumerateNear >= 0 {
+		printEnumerateNearOptimal(os.Stdout, packages, ctx, opts.enumerateNear)
+		return 0
+	}
+
+	if opts.nearOptimal > 0 {
+		printNearOptimal(os.Stdout, packages, ctx, opts.nearOptimal)
+		return 0
+	}
+
+	if opts.allOptima {
+		printAllOptima(os.Stdout, packages, ctx)
+		return 0
+	}
+
+	if opts.secondBest {
+		printSecondBest(os.Stdout, packages, ctx)
+		return 0
+	}
+
+	if opts.compareSeeds > 0 {
+		generator := NewEmailBasedPackageGenerator()
+		generator.DomainWeight = opts.domainWeight
+		baseline, rows := CompareSeeds(opts.email, opts.compareSeeds, generator, optimizer, ctx)
+		printSeedComparison(os.Stdout, baseline, rows)
+		return 0
+	}
+
+	if opts.searchSpace {
+		printSearchSpace(os.Stdout, packages, ctx.MaxLoad)
+		return 0
+	}
+
+	if opts.fingerprint {
+		fmt.Println(CatalogFingerprint(packages))
+		return 0
+	}
+
+	if opts.packageSetHash {
+		fmt.Println(PackageSetHash(packages))
+		return 0
+	}
+
+	if opts.catalogStats {
+		printCatalogStats(os.Stdout, packages, ctx)
+		return 0
+	}
+
+	if opts.priorityMatrix {
+		printPriorityMatrix(os.Stdout, packages, ctx)
+		return 0
+	}
+
+	if opts.leaveOneOut {
+		printLeaveOneOut(os.Stdout, packages, ctx)
+		return 0
+	}
+
+	if opts.algo == "dp" {
+		if err := checkMemoryLimit(len(packages)+1, ctx.MaxLoad, opts.memoryLimit); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !allocationConfirmed(len(packages)+1, ctx.MaxLoad, opts.yes) {
+			fmt.Fprintln(os.Stderr, "aborted")
+			return 1
+		}
+	}
+
+	var events *eventEmitter
+	if opts.emitEvents {
+		events = newEventEmitter(os.Stdout, opts.email)
+		events.emit("PackagesGenerated", map[string]int{"count": len(packages)})
+		events.emit("OptimizationStarted", map[string]any{"algorithm": opts.algo, "capacity": ctx.MaxLoad})
+	}
+
+	if opts.explainGreedy {
+		if greedy, ok := solver.(*GreedyOptimizer); ok {
+			greedy.OnStep = explainGreedyStep(diagOut)
+		} else {
+			warnf("-explain-greedy only applies to -algo greedy")
+		}
+	}
+
+	var anneal *annealTrace
+	if opts.explainSA {
+		if sa, ok := solver.(*SimulatedAnnealingOptimizer); ok {
+			anneal = &annealTrace{w: diagOut}
+			sa.OnMove = anneal.Record
+		} else {
+			warnf("-explain-sa only applies to -algo sa")
+		}
+	}
+
+	var plot *terminalPlot
+	if sa, ok := solver.(*SimulatedAnnealingOptimizer); ok && opts.interactivePlot && !opts.noProgress && isTerminal(os.Stdout) {
+		plot = newTerminalPlot(os.Stdout)
+		sa.OnProgress = plot.Record
+	}
+
+	if opts.warmup > 0 {
+		rng := rand.New(rand.NewSource(opts.randSeed))
+		took := warmUp(func() LoadOptimizer { return optimizers[opts.algo](opts) }, opts.warmup, ctx, rng)
+		verbosef("warmup: %d runs in %s", opts.warmup, took.Round(time.Microsecond))
+	}
+
+	// Perform optimization
+	timer.mark("setup")
+	selected := optimizer.Optimize(packages, ctx)
+	timer.mark("optimization")
+	if plot != nil {
+		plot.Stop()
+	}
+	if anneal != nil {
+		anneal.printHistogram()
+	}
+	if opts.retryIncrement > 0 && len(selected) == 0 {
+		for retry := 1; retry <= maxEmptyRetries && len(selected) == 0; retry++ {
+			ctx.MaxLoad += opts.retryIncrement
+			verbosef("empty selection, retry %d at capacity %s", retry, num(ctx.MaxLoad))
+			selected = optimizer.Optimize(packages, ctx)
+		}
+		infof("final capacity: %s", num(ctx.MaxLoad))
+	}
+	if lazy, ok := solver.(*LazyDP); ok {
+		verbosef("lazy DP computed %d of %d cells", lazy.CellsComputed, len(packages)*(ctx.MaxLoad+1))
+	}
+	if opts.costBudget > 0 {
+		infof("total cost: %s of budget %s", num(totalCost(selected)), num(opts.costBudget))
+	}
+	if err := chain.failure(selected); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if bonus, ok := solver.(*GroupAwareBonusOptimizer); ok {
+		if groups, extra := bonus.EarnedBonuses(selected); extra > 0 {
+			infof("group bonus: +%s from %s (objective %s)", num(extra), strings.Join(groups, ","), num(totalValue(selected)+extra))
+		}
+	}
+	if _, ok := solver.(*MinWeightOptimizer); ok {
+		infof("minimum weight for value %s: %s", num(opts.targetValue), num(totalMass(selected)))
+	}
+	if early, ok := solver.(*EarlyTerminationDP); ok {
+		verbosef("early termination after %d of %d rows", early.Rows, len(packages))
+	}
+	// the result references the true truck size, reserve included
+	truck := ctx
+	truck.MaxLoad += opts.reserve
+	if policy != nil {
+		selected, err = applyPolicy(policy, packages, selected, ctx.MaxLoad, func(sel []PackageMetadata) OptimizationResult {
+			return newResult(opts.email, opts.algo, truck, sel)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	events.emitSelection(packages, selected)
+	verbosef("%s selected %d packages: mass=%s value=%s", opts.algo, len(selected), num(totalMass(selected)), num(totalValue(selected)))
+	if mass := totalMass(selected); opts.flexPercent > 0 && mass > nominal {
+		warnf("selection uses flex capacity: mass %s exceeds capacity %s by %s", num(mass), num(nominal), num(mass-nominal))
+	}
+	if opts.reserve > 0 {
+		infof("utilization: %s of %s (%s%%), %s reserved",
+			num(totalMass(selected)), num(truck.MaxLoad), fnum(100*float64(totalMass(selected))/float64(truck.MaxLoad), 1), num(opts.reserve))
+	}
+	result := newResult(opts.email, opts.algo, truck, selected)
+	if opts.noSortOutput {
+		result.Selected = identifiers(selected)
+	}
+	if opts.validateResult {
+		if err := ValidateResult(packages, result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		verbosef("result validated")
+	}
+	result.applyAliases(opts.aliases)
+	result.Approximate = deadline != nil && deadline.TimedOut
+	result.PackageSetHash = PackageSetHash(packages)
+	verbosef("package set hash: %s", result.PackageSetHash)
+	if opts.countOptimal {
+		result.OptimalCount = CountOptimalSolutions(packages, ctx.MaxLoad)
+		infof("optimal solutions: %s", num(int(result.OptimalCount)))
+	}
+	if opts.includeStats {
+		result.Stats = computeStats(packages, selected, ctx, solver)
+	}
+	if opts.emitTestcase {
+		if err := writeTestCase(os.Stdout, opts.email, packages, ctx, result); err != nil {
+			fmt.Fprintln(os.Stderr, "emit testcase:", err)
+			return 1
+		}
+	} else {
+		if opts.verboseJSON {
+			fmt.Fprintln(diagOut, TableFormatter{}.Format(result))
+		}
+		if _, err := io.WriteString(os.Stdout, formatters[opts.format](opts).Format(result)); err != nil {
+			fmt.Fprintln(os.Stderr, "write result:", err)
+			return 1
+		}
+		if opts.resultHash {
+			fmt.Printf("|%s", resultHMAC(result.Selected, []byte(opts.resultSignKey)))
+		}
+	}
+	timer.mark("output formatting")
+	if opts.reportTiming || diagLevel >= levelVerbose {
+		timer.print(diagOut, "phase")
+		if opts.algo == "dp" {
+			timeDPPhases(dpLayouts[opts.dpLayout], packages, ctx.MaxLoad, false).print(diagOut, "dp replay")
+		}
+	}
+	if opts.traceAllocs {
+		timer.printAllocations(diagOut, "phase")
+		if opts.algo == "dp" {
+			timeDPPhases(dpLayouts[opts.dpLayout], packages, ctx.MaxLoad, true).printAllocations(diagOut, "dp replay")
+		}
+	}
+
+	if opts.simulateChallenge {
+		return simulateChallenge(opts, result)
+	}
+
+	if opts.persist != "" {
+		stored := true
+		var err error
+		if opts.persistImproved {
+			stored, err = persistImprovement(opts.persist, result, time.Now())
+		} else {
+			err = appendResult(opts.persist, result, time.Now())
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "persist:", err)
+			return 1
+		}
+		if !stored {
+			verbosef("persist: value %s does not beat the stored best; not saved", num(result.TotalValue))
+		}
+	}
+
+	if opts.jsonFile != "" {
+		if err := writeJSONFile(opts.jsonFile, result, opts.compactOutput); err != nil {
+			fmt.Fprintln(os.Stderr, "json file:", err)
+			return 1
+		}
+	}
+
+	if opts.emitLatex != "" {
+		if err := emitLatex(opts.emitLatex, packages, selected); err != nil {
+			fmt.Fprintln(os.Stderr, "emit latex:", err)
+			return 1
+		}
+	}
+
+	if opts.testCase != "" {
+		path, err := writeTestFixture(opts.testCase, packages, result, opts.updateTestCase)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "test case:", err)
+			return 1
+		}
+		infof("wrote %s", path)
+	}
+
+	if opts.valueHistogram {
+		printValueHistogram(diagOut, selected)
+	}
+
+	if opts.topOff {
+		printTopOff(diagOut, packages, selected, ctx.MaxLoad)
+	}
+
+	if opts.efficiency {
+		printEfficiency(diagOut, selected)
+	}
+
+	if opts.weightUtilization {
+		printWeightUtilization(diagOut, selected)
+	}
+
+	if opts.itemContribution {
+		printItemContributions(diagOut, packages, selected, ctx)
+	}
+
+	if opts.benchmarkDP {
+		printDPComparison(diagOut, packages, selected, ctx)
+	}
+
+	if opts.traceBacktrack {
+		traceBacktrack(diagOut, packages, ctx.MaxLoad)
+	}
+
+	if opts.explainBacktrack {
+		explainBacktrack(diagOut, packages, ctx.MaxLoad)
+	}
+
+	if opts.dpRowDiff {
+		showDPRowDiff(diagOut, packages, ctx.MaxLoad)
+	}
+
+	if opts.explainImproved {
+		explainImprovements(diagOut, packages, ctx.MaxLoad)
+	}
+
+	if opts.explainPriority {
+		explainPriority(diagOut, packages, ctx.PriorityFactor)
+	}
+
+	if opts.showOptimalPath {
+		printOptimalPath(diagOut, OptimalPath(packages, ctx.MaxLoad))
+	}
+
+	if opts.archive != "" {
+		cfg := runConfig{Email: opts.email, Algorithm: opts.algo, MaxLoad: ctx.MaxLoad, PriorityFactor: ctx.PriorityFactor}
+		if err := writeArchive(opts.archive, packages, cfg, result); err != nil {
+			fmt.Fprintln(os.Stderr, "archive:", err)
+			return 1
+		}
+	}
+
+	if opts.checkOptimality {
+		printOptimalityConditions(diagOut, packages, selected, ctx.MaxLoad)
+	}
+
+	if opts.checkMonotone {
+		dp := dpLayouts[opts.dpLayout](len(packages)+1, ctx.MaxLoad+1)
+		fillTable(dp, packages, ctx.MaxLoad)
+		if err := checkMonotonicity(dp, len(packages), ctx.MaxLoad); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		verbosef("DP monotone in capacity over [0, %s]", num(ctx.MaxLoad))
+	}
+
+	if opts.checkSymmetry {
+		if err := checkDPSymmetry(packages, ctx.MaxLoad); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		verbosef("DP symmetric under swaps of %d duplicated packages", len(packages))
+	}
+
+	if opts.checkBounds {
+		if err := checkBounds(packages, ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		verbosef("greedy <= DP optimum <= LP bound at capacity %s", num(ctx.MaxLoad))
+	}
+
+	if opts.packageShuffle != 0 {
+		if err := checkOrderIndependence(optimizers[opts.algo](opts), packages, ctx, opts.packageShuffle); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		verbosef("value unchanged with packages shuffled by seed %d", opts.packageShuffle)
+	}
+
+	if opts.failOnEmpty && len(selected) == 0 {
+		fmt.Fprintf(os.Stderr, "no packages fit capacity %d; increase -capacity or use lighter packages\n", ctx.MaxLoad)
+		return 1
+	}
+
+	if opts.failOnSuboptimal >= 0 {
+		// the reference solves the same problem exactly: the same constrained
+		// DP, value cap and forced packages, scored the way they score
+		reference := constrainedOptimizer(opts)
+		if reference == nil {
+			reference = &PriorityBasedOptimizer{}
+		}
+		score := totalValue
+		if opts.valueCap > 0 {
+			reference = &ValueCapOptimizer{Inner: reference, Cap: opts.valueCap}
+			score = func(pkgs []PackageMetadata) int { return cappedValue(pkgs, opts.valueCap) }
+		}
+		if len(opts.forceInclude) > 0 {
+			reference = &ForcedOptimizer{Inner: reference, Forced: opts.forceInclude}
+		}
+		gap := score(reference.Optimize(packages, ctx)) - score(selected)
+		if gap > opts.failOnSuboptimal {
+			fmt.Fprintf(os.Stderr, "suboptimal result: %s is %d value units below the DP optimum (margin %d)\n", opts.algo, gap, opts.failOnSuboptimal)
+			return 1
+		}
+	}
+	return 0
+}
+
+// loadPackages reads the -catalog file or generates the email's packages
+func loadPackages(opts *options) ([]PackageMetadata, error) {
+	if opts.inlinePackages != "" {
+		return parseInlineCatalog(opts.inlinePackages)
+	}
+	if opts.catalog != "" {
+		return loadCatalog(opts.catalog, opts.skipBadRows, opts.strictJSON)
+	}
+	if opts.gitCatalog != "" {
+		return loadGitCatalog(opts.gitCatalog, opts.skipBadRows, opts.strictJSON)
+	}
+
+	// Initialize package generator
+	generator := NewEmailBasedPackageGenerator()
+	generator.DomainWeight = opts.domainWeight
+	packages := generator.Generate(opts.email)
+	if opts.verifyOrder {
+		mustBeInCatalogOrder(generator.basePackages, packages)
+	}
+	if opts.checkSeedRange {
+		if err := CheckSeedRange(generator.basePackages, packages); err != nil {
+			return nil, err
+		}
+	}
+	if opts.generateN > 0 {
+		packages = appendSynthetic(packages, opts.generateN, opts.capacity, computeWeightedSeed(opts.email, opts.domainWeight))
+	}
+	if opts.rejectDegenerate {
+		if err := CheckDegenerate(generator.basePackages, packages); err != nil {
+			return nil, err
+		}
+	}
+	if opts.noX || opts.noY || opts.onlyDynamic {
+		packages = selectDynamic(packages, len(generator.basePackages), opts.noX, opts.noY, opts.onlyDynamic)
+	}
+	return packages, nil
+}
+
+// formatSelection joins the identifiers alphabetically, or reports that nothing fits
+func formatSelection(selected []PackageMetadata) string {
+	// sort alphabetically
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Identifier < selected[j].Identifier
+	})
+
+	// Format output
+	identifiers := make([]string, len(selected))
+	for i, pkg := range selected {
+		identifiers[i] = pkg.Identifier
+	}
+
+	if len(identifiers) == 0 {
+		return "No viable packages"
+	}
+	return strings.Join(identifiers, ",")
+}
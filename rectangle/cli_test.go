@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestWrappedInfeasibleFails checks that wrapping a constrained optimizer in
+// -timeout, -package-value-cap, -randomize-identifiers or -force-include
+// still reports its infeasibility instead of an empty result
+func TestWrappedInfeasibleFails(t *testing.T) {
+	constrained := [][]string{
+		{"-target-value", "100000"},
+		{"-target-value", "100000", "-early-termination"},
+		{"-mass-parity", "odd", "-capacity", "1"},
+		{"-choose-one", "C,E", "-capacity", "20"},
+	}
+	wrappers := [][]string{
+		nil,
+		{"-timeout", "5s"},
+		{"-package-value-cap", "100"},
+		{"-randomize-identifiers", "3"},
+		{"-force-include", "F"},
+	}
+	for _, c := range constrained {
+		for _, w := range wrappers {
+			args := append(append(append([]string{"-quiet"}, c...), w...), "test@example.com")
+			if code := run(args); code != 1 {
+				t.Errorf("run %v = %d, want 1", args[1:], code)
+			}
+		}
+	}
+}
+
+// TestFailOnSuboptimalConstrained checks that -fail-on-suboptimal compares
+// with the optimum of the constrained problem, not the unconstrained DP
+func TestFailOnSuboptimalConstrained(t *testing.T) {
+	for _, c := range [][]string{
+		{"-mass-parity", "odd"},
+		{"-total-cost-constraint", "20"},
+		{"-choose-one", "C,E"},
+		{"-reserve", "10"},
+		{"-force-include", "C"},
+		{"-package-value-cap", "70"},
+	} {
+		args := append(append([]string{"-quiet", "-fail-on-suboptimal", "0"}, c...), "test@example.com")
+		if code := run(args); code != 0 {
+			t.Errorf("run %v = %d, want 0", args[1:], code)
+		}
+	}
+	// greedy misses the forced optimum by 20 for this email
+	if code := run([]string{"-quiet", "-fail-on-suboptimal", "0", "-algo", "greedy", "-force-include", "C", "test@example.com"}); code != 1 {
+		t.Errorf("suboptimal greedy with -force-include exited %d, want 1", code)
+	}
+}
+
+func TestNoSortOutputOrder(t *testing.T) {
+	for _, email := range []string{"test@example.com", "a@b.c", "someone@example.org"} {
+		path := filepath.Join(t.TempDir(), "result.json")
+		if code := run([]string{"-quiet", "-no-sort-output", "-json-file", path, email}); code != 0 {
+			t.Fatalf("%s: run = %d", email, code)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result OptimizationResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			t.Fatal(err)
+		}
+
+		// walk the DP table from the last item to the first, as backtracking does
+		pkgs := NewEmailBasedPackageGenerator().Generate(email)
+		dp := buildTable(pkgs, defaultMaxLoad)
+		var want []string
+		for i, w := len(pkgs), defaultMaxLoad; i > 0; i-- {
+			if p := pkgs[i-1]; p.MassConstraint <= w && dp[i][w] == dp[i-1][w-p.MassConstraint]+p.Valuation {
+				want = append(want, p.Identifier)
+				w -= p.MassConstraint
+			}
+		}
+		if !slices.Equal(result.Selected, want) {
+			t.Errorf("%s: selected %v, want backtracking order %v", email, result.Selected, want)
+		}
+	}
+}
+
+func TestWhatIfCapacityBelowZero(t *testing.T) {
+	for _, delta := range []string{"-51", "-60"} {
+		if _, err := parseOptions([]string{"-what-if-capacity", delta, "test@example.com"}); err == nil {
+			t.Errorf("-what-if-capacity %s at capacity 50 was accepted", delta)
+		}
+	}
+	if _, err := parseOptions([]string{"-what-if-capacity", "-40", "-reserve", "20", "test@example.com"}); err == nil {
+		t.Error("-what-if-capacity -40 with 30 left after the reserve was accepted")
+	}
+	if code := run([]string{"-quiet", "-what-if-capacity", "-50", "test@example.com"}); code != 0 {
+		t.Errorf("-what-if-capacity -50 exited %d, want 0", code)
+	}
+}
+
+func TestMaxEmailLength(t *testing.T) {
+	long := strings.Repeat("a", 100000-len("@example.com")) + "@example.com"
+	for _, args := range [][]string{
+		{long},
+		{"-max-email-length", "99999", long},
+		{"-robust", "test@example.com", long},
+	} {
+		if _, err := parseOptions(args); err == nil || !strings.Contains(err.Error(), "100000 bytes") {
+			t.Errorf("%.40s: err = %v, want the 100000 byte email rejected", strings.Join(args, " "), err)
+		}
+	}
+	for _, args := range [][]string{{"-max-email-length", "100000", long}, {"-max-email-length", "0", long}} {
+		if _, err := parseOptions(args); err != nil {
+			t.Errorf("-max-email-length %s: %v", args[1], err)
+		}
+	}
+
+	// in a batch the long email fails alone
+	batch := filepath.Join(t.TempDir(), "emails.txt")
+	if err := os.WriteFile(batch, []byte("test@example.com\n"+long+"\na@b.c\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"-quiet", "-batch", batch}) })
+	if code != 1 || strings.Count(out, "\n") != 2 || strings.Contains(out, long[:40]) {
+		t.Errorf("batch exited %d with %q, want 1 and the two short emails", code, out)
+	}
+}
+
+func TestNegativeTopK(t *testing.T) {
+	if _, err := parseOptions([]string{"-top-k", "-1", "test@example.com"}); err == nil || !strings.Contains(err.Error(), "top-k") {
+		t.Errorf("-top-k -1: err = %v, want a top-k usage error", err)
+	}
+	if code := run([]string{"-quiet", "-top-k", "-3", "test@example.com"}); code != 1 {
+		t.Errorf("run -top-k -3 = %d, want 1", code)
+	}
+	for _, k := range []string{"0", "3"} {
+		if _, err := parseOptions([]string{"-top-k", k, "test@example.com"}); err != nil {
+			t.Errorf("-top-k %s: %v", k, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+)
+
+// SeedCollision is a pair of distinct emails that produce the same seed
+type SeedCollision struct {
+	First, Second string
+	Seed          uint64
+}
+
+// randomEmail builds a random lowercase address like "k3x9q@a7.com"
+func randomEmail(rng *rand.Rand) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	word := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return string(b)
+	}
+	return word(4+rng.Intn(8)) + "@" + word(3+rng.Intn(6)) + ".com"
+}
+
+// FindSeedCollisions seeds n random emails and reports any that share a seed
+func FindSeedCollisions(n int, rng *rand.Rand) []SeedCollision {
+	seen := make(map[uint64]string, n)
+	var collisions []SeedCollision
+	for i := 0; i < n; i++ {
+		email := randomEmail(rng)
+		seed := computeSeed(email)
+		if prev, ok := seen[seed]; ok && prev != email {
+			collisions = append(collisions, SeedCollision{First: prev, Second: email, Seed: seed})
+			continue
+		}
+		seen[seed] = email
+	}
+	return collisions
+}
+
+// birthdayCollisionProbability estimates P(any collision) among n uniform 64-bit seeds
+func birthdayCollisionProbability(n int) float64 {
+	pairs := float64(n) * float64(n-1) / 2
+	return -math.Expm1(-pairs / math.Exp2(64))
+}
+
+// printSeedCollisions writes the collision audit report
+func printSeedCollisions(w io.Writer, n int, rng *rand.Rand) {
+	collisions := FindSeedCollisions(n, rng)
+	fmt.Fprintf(w, "checked %s random emails: %s seed collisions\n", num(n), num(len(collisions)))
+	for _, c := range collisions {
+		fmt.Fprintf(w, "  %s and %s -> seed %d\n", c.First, c.Second, c.Seed)
+	}
+	fmt.Fprintf(w, "birthday-bound collision probability: %.3g\n", birthdayCollisionProbability(n))
+}
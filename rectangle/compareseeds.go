@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// compareSeedSalts are appended to the email to derive alternative seeds for
+// -compare-seeds; fixed so reports are reproducible across runs
+var compareSeedSalts = []string{
+	"#alpha", "#bravo", "#charlie", "#delta", "#echo", "#foxtrot", "#golf", "#hotel",
+	"#india", "#juliett", "#kilo", "#lima", "#mike", "#november", "#oscar", "#papa",
+}
+
+// SeedComparison is the optimum for one salted variant of the email
+type SeedComparison struct {
+	Salt     string
+	Seed     uint64
+	Selected string // sorted comma-joined identifiers
+	Value    int
+	Changed  bool // differs from the unsalted selection
+}
+
+// CompareSeeds optimizes the catalogs generated from the email with each of
+// the first count salts and compares each selection with the unsalted one
+func CompareSeeds(email string, count int, generator *EmailBasedPackageGenerator, optimizer LoadOptimizer, ctx HeuristicContext) (baseline string, rows []SeedComparison) {
+	baseline = selectionKey(optimizer.Optimize(generator.Generate(email), ctx))
+	for _, salt := range compareSeedSalts[:count] {
+		salted := email + salt
+		selected := optimizer.Optimize(generator.Generate(salted), ctx)
+		ids := selectionKey(selected)
+		rows = append(rows, SeedComparison{
+			Salt:     salt,
+			Seed:     computeWeightedSeed(salted, generator.DomainWeight),
+			Selected: ids,
+			Value:    totalValue(selected),
+			Changed:  ids != baseline,
+		})
+	}
+	return baseline, rows
+}
+
+// selectionKey is the selection as sorted comma-joined identifiers
+func selectionKey(selected []PackageMetadata) string {
+	ids := make([]string, len(selected))
+	for i, p := range selected {
+		ids[i] = p.Identifier
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+// printSeedComparison writes each salted result and how often the selection changed
+func printSeedComparison(w io.Writer, baseline string, rows []SeedComparison) {
+	fmt.Fprintf(w, "unsalted: %s\n", baseline)
+	changed := 0
+	distinct := map[string]bool{baseline: true}
+	for _, r := range rows {
+		mark := "same"
+		if r.Changed {
+			mark = "changed"
+			changed++
+		}
+		distinct[r.Selected] = true
+		fmt.Fprintf(w, "%-10s seed=%-20d value=%-4s %-7s %s\n", r.Salt, r.Seed, num(r.Value), mark, r.Selected)
+	}
+	fmt.Fprintf(w, "changed in %d of %d seeds (%s%%), %d distinct selections\n",
+		changed, len(rows), fnum(100*float64(changed)/float64(len(rows)), 1), len(distinct))
+}
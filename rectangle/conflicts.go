@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+)
+
+// ConflictPair is an unordered pair of catalog packages linked by ExcludedBy
+type ConflictPair struct {
+	First, Second PackageMetadata
+}
+
+// conflictPairs lists each ExcludedBy pair once, in catalog order, skipping
+// identifiers that are not in the catalog
+func conflictPairs(pkgs []PackageMetadata) []ConflictPair {
+	byID := make(map[string]PackageMetadata, len(pkgs))
+	for _, p := range pkgs {
+		byID[p.Identifier] = p
+	}
+	seen := make(map[[2]string]bool)
+	var pairs []ConflictPair
+	for _, p := range pkgs {
+		for _, id := range p.ExcludedBy {
+			other, ok := byID[id]
+			if !ok || id == p.Identifier {
+				continue
+			}
+			key := [2]string{p.Identifier, id}
+			if id < p.Identifier {
+				key = [2]string{id, p.Identifier}
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			pairs = append(pairs, ConflictPair{First: p, Second: other})
+		}
+	}
+	return pairs
+}
+
+// ConflictResolution records one wanted pair and the package dropped to settle it
+type ConflictResolution struct {
+	Pair       ConflictPair
+	Kept       PackageMetadata
+	Dropped    PackageMetadata
+	AlreadyOut bool // an earlier resolution had already dropped one of the pair
+}
+
+// ResolveConflicts propagates the conflicts through the unconstrained greedy
+// selection: for every pair the greedy solution takes both of, the lower
+// valued package is dropped. It returns the repaired selection and one
+// resolution per wanted pair.
+func ResolveConflicts(pkgs []PackageMetadata, ctx HeuristicContext) ([]PackageMetadata, []ConflictResolution) {
+	greedy := (&GreedyOptimizer{}).Optimize(pkgs, ctx)
+	in := make(map[string]bool, len(greedy))
+	for _, p := range greedy {
+		in[p.Identifier] = true
+	}
+	wanted := maps.Clone(in)
+
+	var resolutions []ConflictResolution
+	for _, pair := range conflictPairs(pkgs) {
+		if !wanted[pair.First.Identifier] || !wanted[pair.Second.Identifier] {
+			continue
+		}
+		kept, dropped := pair.First, pair.Second
+		if dropped.Valuation > kept.Valuation {
+			kept, dropped = dropped, kept
+		}
+		r := ConflictResolution{Pair: pair, Kept: kept, Dropped: dropped}
+		if !in[pair.First.Identifier] || !in[pair.Second.Identifier] {
+			r.AlreadyOut = true
+		} else {
+			in[dropped.Identifier] = false
+		}
+		resolutions = append(resolutions, r)
+	}
+
+	var selection []PackageMetadata
+	for _, p := range greedy {
+		if in[p.Identifier] {
+			selection = append(selection, p)
+		}
+	}
+	return selection, resolutions
+}
+
+// printExplainConflicts reports every conflict pair, which of them the greedy
+// solution wanted both of, and how each was resolved at what value cost
+func printExplainConflicts(w io.Writer, pkgs []PackageMetadata, ctx HeuristicContext) {
+	pairs := conflictPairs(pkgs)
+	if len(pairs) == 0 {
+		fmt.Fprintln(w, "conflicts: none declared")
+		return
+	}
+	fmt.Fprintf(w, "conflicts: %d pairs declared\n", len(pairs))
+	selection, resolutions := ResolveConflicts(pkgs, ctx)
+	if len(resolutions) == 0 {
+		fmt.Fprintln(w, "no pair is wanted together by the greedy solution")
+	}
+	cost := 0
+	for _, r := range resolutions {
+		fmt.Fprintf(w, "wanted together: %s (value %s) and %s (value %s): ",
+			r.Pair.First.Identifier, num(r.Pair.First.Valuation), r.Pair.Second.Identifier, num(r.Pair.Second.Valuation))
+		if r.AlreadyOut {
+			fmt.Fprintln(w, "already resolved by an earlier drop")
+			continue
+		}
+		fmt.Fprintf(w, "kept %s, dropped %s, cost %s\n", r.Kept.Identifier, r.Dropped.Identifier, num(r.Dropped.Valuation))
+		cost += r.Dropped.Valuation
+	}
+	fmt.Fprintf(w, "conflict-free selection: %s (value %s, resolution cost %s)\n", formatSelection(selection), num(totalValue(selection)), num(cost))
+}
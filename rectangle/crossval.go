@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// FoldResult is the optimizer's outcome with one fold held out
+type FoldResult struct {
+	HeldOut  []string
+	Selected []PackageMetadata
+}
+
+// CrossValidate shuffles the packages into k folds and optimizes k times,
+// each time on every package except one fold, as in k-fold cross-validation.
+// Training sets overlap, so a stable optimizer keeps picking the same items.
+func CrossValidate(pkgs []PackageMetadata, k int, optimizer LoadOptimizer, ctx HeuristicContext, rng *rand.Rand) []FoldResult {
+	order := rng.Perm(len(pkgs))
+	results := make([]FoldResult, k)
+	for fold := range results {
+		var train []PackageMetadata
+		for pos, i := range order {
+			if pos%k == fold {
+				results[fold].HeldOut = append(results[fold].HeldOut, pkgs[i].Identifier)
+			} else {
+				train = append(train, pkgs[i])
+			}
+		}
+		sort.Strings(results[fold].HeldOut)
+		results[fold].Selected = optimizer.Optimize(train, ctx)
+	}
+	return results
+}
+
+// topByValue returns the identifiers of the n most valuable packages
+func topByValue(pkgs []PackageMetadata, n int) []string {
+	sorted := append([]PackageMetadata(nil), pkgs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Valuation > sorted[j].Valuation })
+	ids := make([]string, 0, n)
+	for _, p := range sorted[:min(n, len(sorted))] {
+		ids = append(ids, p.Identifier)
+	}
+	return ids
+}
+
+// printCrossValidation writes each fold's result followed by consistency
+// metrics: how often each package makes a fold's top 3 by value, the
+// mean and variance of the total value, and the number of empty folds
+func printCrossValidation(w io.Writer, results []FoldResult) {
+	topCount := make(map[string]int)
+	values := make([]float64, len(results))
+	empty := 0
+	for i, r := range results {
+		top := topByValue(r.Selected, 3)
+		for _, id := range top {
+			topCount[id]++
+		}
+		values[i] = float64(totalValue(r.Selected))
+		if len(r.Selected) == 0 {
+			empty++
+		}
+		fmt.Fprintf(w, "fold %d: held out %-8s value=%-4s top3=%-8s selected %s\n",
+			i+1, strings.Join(r.HeldOut, ","), num(totalValue(r.Selected)), strings.Join(top, ","), formatSelection(r.Selected))
+	}
+
+	ids := make([]string, 0, len(topCount))
+	for id := range topCount {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if topCount[ids[i]] != topCount[ids[j]] {
+			return topCount[ids[i]] > topCount[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	fmt.Fprintln(w, "top-3 appearances:")
+	for _, id := range ids {
+		fmt.Fprintf(w, "  %-4s %d/%d folds\n", id, topCount[id], len(results))
+	}
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v / float64(len(values))
+	}
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean) / float64(len(values))
+	}
+	fmt.Fprintf(w, "value mean: %s variance: %s\n", fnum(mean, 2), fnum(variance, 2))
+	fmt.Fprintf(w, "empty folds: %d\n", empty)
+}
@@ -4,7 +4,6 @@
 package main
 
 import (
-	"fmt"
 	"math"
 	"os"
 	"sort"
@@ -16,6 +15,10 @@ type PackageMetadata struct {
 	Identifier     string
 	MassConstraint int
 	Valuation      int
+	Dependencies   []string `json:",omitempty"` // identifiers that must be loaded alongside this package
+	ExcludedBy     []string `json:",omitempty"` // identifiers that cannot share the truck with this package
+	Category       string   `json:",omitempty"` // optional grouping for -category-value-cap
+	Cost           int      `json:",omitempty"` // budget consumed, for -total-cost-constraint
 }
 
 // HeuristicContext holds optimization parameters
@@ -32,6 +35,8 @@ type DynamicPackageGenerator interface {
 // EmailBasedPackageGenerator implements package generation
 type EmailBasedPackageGenerator struct {
 	basePackages []PackageMetadata
+	// DomainWeight scales the seed contribution of runes after the @; 0 and 1 are uniform
+	DomainWeight uint64
 }
 
 // NewEmailBasedPackageGenerator initializes the generator
@@ -54,15 +59,7 @@ func (g *EmailBasedPackageGenerator) Generate(email string) []PackageMetadata {
 	pkgs := make([]PackageMetadata, len(g.basePackages))
 	copy(pkgs, g.basePackages)
 
-	// Compute a pseudo-random seed from email using LCG-style accumulation for variability
-	// This ensures robust distribution across large input spaces
-	var seed uint64 = 0
-	const multiplier uint64 = 0x5DEECE66D // Large constant to promote wide distribution
-	const adder uint64 = 0xB              // Small additive constant
-	for _, r := range email {
-		seed = seed*multiplier + uint64(r) + adder
-		// No explicit modulo; rely on natural uint64 wraparound for consistency
-	}
+	seed := computeWeightedSeed(email, g.DomainWeight)
 
 	// Append dynamic packages with computed attributes
 	// NOTE: Do not modify the constraints and valuations of the dynamic packages
@@ -76,105 +73,363 @@ func (g *EmailBasedPackageGenerator) Generate(email string) []PackageMetadata {
 		MassConstraint: int((seed % 10) + 8),
 		Valuation:      int((seed % 40) + 50),
 	})
+	assignCosts(pkgs, seed)
 
 	return pkgs
 }
 
+// assignCosts gives every package a cost in [1, 20] by continuing the
+// seed's LCG once per package; masses and valuations are left untouched
+func assignCosts(pkgs []PackageMetadata, seed uint64) {
+	for i := range pkgs {
+		seed = seed*0x5DEECE66D + 0xB
+		pkgs[i].Cost = int((seed>>33)%20) + 1
+	}
+}
+
+// computeSeed derives the generator seed from an email
+func computeSeed(email string) uint64 {
+	return computeWeightedSeed(email, 1)
+}
+
+// computeWeightedSeed is computeSeed with the domain runes (after the last @)
+// multiplied by domainWeight; emails without an @ are all local part
+func computeWeightedSeed(email string, domainWeight uint64) uint64 {
+	if domainWeight == 0 {
+		domainWeight = 1
+	}
+	domainStart := strings.LastIndexByte(email, '@') + 1
+	if domainStart == 0 {
+		domainStart = len(email)
+	}
+
+	// Compute a pseudo-random seed from email using LCG-style accumulation for variability
+	// This ensures robust distribution across large input spaces
+	var seed uint64 = 0
+	const multiplier uint64 = 0x5DEECE66D // Large constant to promote wide distribution
+	const adder uint64 = 0xB              // Small additive constant
+	for i, r := range email {
+		contribution := uint64(r)
+		if i >= domainStart {
+			contribution *= domainWeight
+		}
+		seed = seed*multiplier + contribution + adder
+		// No explicit modulo; rely on natural uint64 wraparound for consistency
+	}
+	return seed
+}
+
 // LoadOptimizer interface for optimization strategies
 type LoadOptimizer interface {
 	Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata
 }
 
 // PriorityBasedOptimizer implements a heuristic-based optimization
-type PriorityBasedOptimizer struct{}
+type PriorityBasedOptimizer struct {
+	// NewTable allocates the DP table storage; nil uses the dense row layout
+	NewTable func(rows, cols int) DPTable
+	// NoAlloc solves instances within smallMaxItems and smallMaxLoad in a
+	// fixed-size array on the stack instead of allocating a table
+	NoAlloc bool
+	// ForwardBacktrack recovers items from first to last, taking each item at
+	// its first opportunity; among several optimal sets this may pick another
+	ForwardBacktrack bool
+	// Counters is the work measured during the last Optimize
+	Counters DPCounters
+}
 
 // Optimize finds the truly optimal set of packages using 0/1 knapsack DP
 func (o *PriorityBasedOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	o.Counters = DPCounters{}
+	if o.ForwardBacktrack {
+		return backtrackForward(pkgs, ctx.MaxLoad, &o.Counters)
+	}
+	if o.NoAlloc && len(pkgs) <= smallMaxItems && ctx.MaxLoad <= smallMaxLoad {
+		return optimizeSmall(pkgs, ctx.MaxLoad, &o.Counters)
+	}
+	newTable := o.NewTable
+	if newTable == nil {
+		newTable = newDenseTable
+	}
+	dp := newTable(len(pkgs)+1, ctx.MaxLoad+1)
+	o.Counters.CellsComputed = fillTable(dp, pkgs, ctx.MaxLoad)
+	selected, steps := backtrackCounted(dp, pkgs, ctx.MaxLoad)
+	o.Counters.BacktrackSteps = steps
+	return selected
+}
+
+// Work returns the counters of the last Optimize
+func (o *PriorityBasedOptimizer) Work() DPCounters { return o.Counters }
+
+// smallMaxItems and smallMaxLoad bound the stack-allocated DP: the default
+// catalog of 8 packages at the default capacity
+const (
+	smallMaxItems = 8
+	smallMaxLoad  = defaultMaxLoad
+)
+
+// optimizeSmall is the 0/1 knapsack DP over a [9][51]int array that stays on
+// the stack, for n <= smallMaxItems and W <= smallMaxLoad; it adds its work to c
+func optimizeSmall(pkgs []PackageMetadata, W int, c *DPCounters) []PackageMetadata {
+	var dp [smallMaxItems + 1][smallMaxLoad + 1]int
 	n := len(pkgs)
-	W := ctx.MaxLoad
+	for i := 1; i <= n; i++ {
+		wt, val := pkgs[i-1].MassConstraint, pkgs[i-1].Valuation
+		for w := 0; w <= W; w++ {
+			dp[i][w] = dp[i-1][w]
+			if wt <= w && dp[i-1][w-wt]+val > dp[i][w] {
+				dp[i][w] = dp[i-1][w-wt] + val
+			}
+			c.CellsComputed++
+		}
+	}
 
-	// dp[i][w] = max value achievable with first i items and capacity w
-	dp := make([][]int, n+1)
+	res := []PackageMetadata{}
+	for i, w := n, W; i > 0; i-- {
+		c.BacktrackSteps++
+		wt, val := pkgs[i-1].MassConstraint, pkgs[i-1].Valuation
+		if wt <= w && dp[i][w] == dp[i-1][w-wt]+val {
+			res = append(res, pkgs[i-1])
+			w -= wt
+		}
+	}
+	return res
+}
+
+// DPTable stores dp[i][w] cells; rows are written in increasing w order
+type DPTable interface {
+	Get(i, w int) int
+	Set(i, w, v int)
+	Bytes() int
+}
+
+// denseTable is the standard row-major [][]int layout
+type denseTable [][]int
+
+func newDenseTable(rows, cols int) DPTable {
+	dp := make(denseTable, rows)
 	for i := range dp {
-		dp[i] = make([]int, W+1)
+		dp[i] = make([]int, cols)
 	}
+	return dp
+}
+
+func (t denseTable) Get(i, w int) int { return t[i][w] }
+func (t denseTable) Set(i, w, v int)  { t[i][w] = v }
+func (t denseTable) Bytes() int       { return len(t) * len(t[0]) * 8 }
+
+// flatTable is one contiguous []int indexed i*cols + w, avoiding a pointer
+// hop and a separate allocation per row
+type flatTable struct {
+	cells []int
+	cols  int
+}
+
+func newFlatTable(rows, cols int) DPTable {
+	return &flatTable{cells: make([]int, rows*cols), cols: cols}
+}
+
+func (t *flatTable) Get(i, w int) int { return t.cells[i*t.cols+w] }
+func (t *flatTable) Set(i, w, v int)  { t.cells[i*t.cols+w] = v }
+func (t *flatTable) Bytes() int       { return len(t.cells) * 8 }
 
-	// fill DP table
+// buildTable fills dp[i][w] = max value achievable with first i items and capacity w
+func buildTable(pkgs []PackageMetadata, W int) [][]int {
+	dp := newDenseTable(len(pkgs)+1, W+1)
+	fillTable(dp, pkgs, W)
+	return dp.(denseTable)
+}
+
+// fillTable runs the 0/1 knapsack recurrence into dp and returns the number
+// of cells it wrote
+func fillTable(dp DPTable, pkgs []PackageMetadata, W int) (cells int) {
+	n := len(pkgs)
 	for i := 1; i <= n; i++ {
 		wt := pkgs[i-1].MassConstraint
 		val := pkgs[i-1].Valuation
 		for w := 0; w <= W; w++ {
-			dp[i][w] = dp[i-1][w] // skip
+			best := dp.Get(i-1, w) // skip
 			if wt <= w {
-				candidate := dp[i-1][w-wt] + val
-				if candidate > dp[i][w] {
-					dp[i][w] = candidate
+				candidate := dp.Get(i-1, w-wt) + val
+				if candidate > best {
+					best = candidate
 				}
 			}
+			dp.Set(i, w, best)
+			cells++
 		}
 	}
+	return cells
+}
 
-	// backtrack to recover which items were chosen
-	res := []PackageMetadata{}
+// backtrack recovers which items were chosen from a filled DP table
+func backtrack(dp [][]int, pkgs []PackageMetadata, W int) []PackageMetadata {
+	return backtrackTable(denseTable(dp), pkgs, W)
+}
+
+// backtrackTable recovers which items were chosen from any DP table layout
+func backtrackTable(dp DPTable, pkgs []PackageMetadata, W int) []PackageMetadata {
+	res, _ := backtrackCounted(dp, pkgs, W)
+	return res
+}
+
+// backtrackCounted is backtrackTable, also returning the number of rows it examined
+func backtrackCounted(dp DPTable, pkgs []PackageMetadata, W int) (res []PackageMetadata, steps int) {
+	res = []PackageMetadata{}
 	w := W
-	for i := n; i > 0; i-- {
+	for i := len(pkgs); i > 0; i-- {
+		steps++
 		wt := pkgs[i-1].MassConstraint
 		val := pkgs[i-1].Valuation
-		if wt <= w && dp[i][w] == dp[i-1][w-wt]+val {
+		if wt <= w && dp.Get(i, w) == dp.Get(i-1, w-wt)+val {
 			res = append(res, pkgs[i-1])
 			w -= wt
 		}
 	}
+	return res, steps
+}
 
+// backtrackForward solves the DP over suffixes, sdp[i][w] being the best
+// value from items i..n-1, so items can be recovered in increasing order:
+// item i is taken whenever taking it still reaches the optimum; it adds its
+// work to c
+func backtrackForward(pkgs []PackageMetadata, W int, c *DPCounters) []PackageMetadata {
+	n := len(pkgs)
+	sdp := newDenseTable(n+1, W+1).(denseTable)
+	for i := n - 1; i >= 0; i-- {
+		wt, val := pkgs[i].MassConstraint, pkgs[i].Valuation
+		for w := 0; w <= W; w++ {
+			sdp[i][w] = sdp[i+1][w]
+			if wt <= w && sdp[i+1][w-wt]+val > sdp[i][w] {
+				sdp[i][w] = sdp[i+1][w-wt] + val
+			}
+			c.CellsComputed++
+		}
+	}
+
+	res := []PackageMetadata{}
+	w := W
+	for i := 0; i < n; i++ {
+		c.BacktrackSteps++
+		wt, val := pkgs[i].MassConstraint, pkgs[i].Valuation
+		if wt <= w && sdp[i][w] == sdp[i+1][w-wt]+val {
+			res = append(res, pkgs[i])
+			w -= wt
+		}
+	}
 	return res
 }
 
-// computePriority calculates a package's priority score
-func computePriority(pkg PackageMetadata, factor float64) float64 {
-	baseRatio := float64(pkg.Valuation) / float64(pkg.MassConstraint)
-	return baseRatio*math.Sqrt(factor) + math.Log1p(float64(pkg.Valuation)) - math.Pow(float64(pkg.MassConstraint), 0.1)
+// GreedyOptimizer implements the fast priority-ordered heuristic
+type GreedyOptimizer struct {
+	// OnStep, if set, is called for every package in the order considered
+	OnStep func(GreedyStep)
+	// Lookahead, if positive, skips a package that fits when the best
+	// combination of the next Lookahead packages is worth more without it
+	Lookahead int
 }
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "Missing configuration parameter")
-		os.Exit(1)
-	}
+// GreedyStep records one greedy decision
+type GreedyStep struct {
+	Package   PackageMetadata
+	Priority  float64
+	Selected  bool
+	Remaining int // capacity left after the decision
+	// LookaheadSkip marks a package that fit but was skipped because the
+	// lookahead window is worth Without on its own and only With alongside it
+	LookaheadSkip bool
+	With, Without int
+}
 
-	config := os.Args[1]
-	if len(config) == 0 {
-		fmt.Fprintln(os.Stderr, "Configuration cannot be empty")
-		os.Exit(1)
-	}
+// Optimize selects packages in descending priority order while they still fit
+func (o *GreedyOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	// Create a working copy to avoid mutating input
+	workingPkgs := make([]PackageMetadata, len(pkgs))
+	copy(workingPkgs, pkgs)
 
-	// Initialize package generator
-	generator := NewEmailBasedPackageGenerator()
-	packages := generator.Generate(config)
+	sort.SliceStable(workingPkgs, func(i, j int) bool {
+		return computePriority(workingPkgs[i], ctx.PriorityFactor) > computePriority(workingPkgs[j], ctx.PriorityFactor)
+	})
 
-	// Configure optimizer with heuristic context
-	optimizer := &PriorityBasedOptimizer{}
-	ctx := HeuristicContext{
-		MaxLoad:        50,
-		PriorityFactor: 1.0, // Neutral factor to avoid scaling issues
+	var selected []PackageMetadata
+	currentLoad := 0
+	for k, pkg := range workingPkgs {
+		take := currentLoad+pkg.MassConstraint <= ctx.MaxLoad
+		var with, without int
+		if take && o.Lookahead > 0 {
+			with, without = o.lookahead(pkg, workingPkgs[k+1:min(k+1+o.Lookahead, len(workingPkgs))], ctx.MaxLoad-currentLoad, ctx)
+			take = with >= without
+		}
+		if take {
+			selected = append(selected, pkg)
+			currentLoad += pkg.MassConstraint
+		}
+		if o.OnStep != nil {
+			step := GreedyStep{Package: pkg, Priority: computePriority(pkg, ctx.PriorityFactor), Selected: take, Remaining: ctx.MaxLoad - currentLoad}
+			if !take && with < without {
+				step.LookaheadSkip, step.With, step.Without = true, with, without
+			}
+			o.OnStep(step)
+		}
 	}
 
-	// Perform optimization
-	selected := optimizer.Optimize(packages, ctx)
+	return selected
+}
 
-	// sort alphabetically
-	sort.Slice(selected, func(i, j int) bool {
-		return selected[i].Identifier < selected[j].Identifier
-	})
+// lookahead returns the value of pkg plus the best of window in the capacity
+// left over, and the value of the best of window alone in the free capacity;
+// pkg is worth taking when the first is at least the second
+func (o *GreedyOptimizer) lookahead(pkg PackageMetadata, window []PackageMetadata, free int, ctx HeuristicContext) (with, without int) {
+	rest := ctx
+	rest.MaxLoad = free - pkg.MassConstraint
+	alone := ctx
+	alone.MaxLoad = free
+	return pkg.Valuation + optimalValue(window, rest), optimalValue(window, alone)
+}
 
-	// Format output
-	identifiers := make([]string, len(selected))
-	for i, pkg := range selected {
-		identifiers[i] = pkg.Identifier
+// totalValue sums the valuations of the given packages
+func totalValue(pkgs []PackageMetadata) int {
+	sum := 0
+	for _, p := range pkgs {
+		sum += p.Valuation
 	}
+	return sum
+}
 
-	if len(identifiers) == 0 {
-		fmt.Print("No viable packages")
-	} else {
-		fmt.Print(strings.Join(identifiers, ","))
+// totalMass sums the mass constraints of the given packages
+func totalMass(pkgs []PackageMetadata) int {
+	sum := 0
+	for _, p := range pkgs {
+		sum += p.MassConstraint
 	}
+	return sum
+}
+
+// computePriority calculates a package's priority score
+func computePriority(pkg PackageMetadata, factor float64) float64 {
+	t := priorityTerms(pkg, factor)
+	return t.Ratio*t.SqrtFactor + t.Log1p - t.Power
+}
+
+// PriorityTerms are the parts of computePriority:
+// Ratio*SqrtFactor + Log1p - Power
+type PriorityTerms struct {
+	Ratio      float64 // value / mass
+	SqrtFactor float64 // sqrt(PriorityFactor)
+	Log1p      float64 // log(1 + value)
+	Power      float64 // mass^0.1
+}
+
+// priorityTerms evaluates each term of the priority formula
+func priorityTerms(pkg PackageMetadata, factor float64) PriorityTerms {
+	return PriorityTerms{
+		Ratio:      float64(pkg.Valuation) / float64(pkg.MassConstraint),
+		SqrtFactor: math.Sqrt(factor),
+		Log1p:      math.Log1p(float64(pkg.Valuation)),
+		Power:      math.Pow(float64(pkg.MassConstraint), 0.1),
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
 }
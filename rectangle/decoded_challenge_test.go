@@ -0,0 +1,97 @@
+package main
+
+import "testing"
+
+func TestDPLayoutsAgree(t *testing.T) {
+	for seed := uint64(1); seed <= 10; seed++ {
+		pkgs := appendSynthetic(nil, 20, 100, seed)
+		ctx := HeuristicContext{MaxLoad: 500}
+		want := totalValue((&PriorityBasedOptimizer{}).Optimize(pkgs, ctx))
+		for name, layout := range dpLayouts {
+			if got := totalValue((&PriorityBasedOptimizer{NewTable: layout}).Optimize(pkgs, ctx)); got != want {
+				t.Errorf("seed %d, %s layout: value %d, want %d", seed, name, got, want)
+			}
+		}
+	}
+}
+
+// BenchmarkDPLayout solves 50 packages at W=50000 with the row ([][]int)
+// and flat (single []int) table layouts
+func BenchmarkDPLayout(b *testing.B) {
+	const W = 50000
+	pkgs := appendSynthetic(nil, 50, W/10, 1)
+	ctx := HeuristicContext{MaxLoad: W}
+	for _, layout := range []string{"row", "flat"} {
+		b.Run(layout, func(b *testing.B) {
+			b.ReportAllocs()
+			opt := &PriorityBasedOptimizer{NewTable: dpLayouts[layout]}
+			for i := 0; i < b.N; i++ {
+				opt.Optimize(pkgs, ctx)
+			}
+		})
+	}
+}
+
+func TestNoAllocMatchesHeapDP(t *testing.T) {
+	generator := NewEmailBasedPackageGenerator()
+	for _, email := range []string{"test@example.com", "a@b.c", "someone@example.org", "x"} {
+		pkgs := generator.Generate(email)
+		for W := 0; W <= smallMaxLoad; W++ {
+			ctx := HeuristicContext{MaxLoad: W}
+			heap := (&PriorityBasedOptimizer{}).Optimize(pkgs, ctx)
+			stack := (&PriorityBasedOptimizer{NoAlloc: true}).Optimize(pkgs, ctx)
+			if totalValue(heap) != totalValue(stack) || totalMass(stack) > W {
+				t.Errorf("%s at %d: stack value %d mass %d, heap value %d", email, W, totalValue(stack), totalMass(stack), totalValue(heap))
+			}
+		}
+	}
+}
+
+// BenchmarkNoAllocDP solves the standard 8-package catalog at capacity 50
+// with a heap-allocated table and with the -no-allocation-dp stack array
+func BenchmarkNoAllocDP(b *testing.B) {
+	pkgs := NewEmailBasedPackageGenerator().Generate("test@example.com")
+	ctx := HeuristicContext{MaxLoad: defaultMaxLoad}
+	for _, tt := range []struct {
+		name    string
+		noAlloc bool
+	}{{"heap", false}, {"stack", true}} {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			opt := &PriorityBasedOptimizer{NoAlloc: tt.noAlloc}
+			for i := 0; i < b.N; i++ {
+				opt.Optimize(pkgs, ctx)
+			}
+		})
+	}
+}
+
+func TestReverseBacktrackSameValue(t *testing.T) {
+	for seed := uint64(1); seed <= 20; seed++ {
+		pkgs := appendSynthetic(nil, 15, 30, seed)
+		for _, W := range []int{0, 20, 75, 200} {
+			ctx := HeuristicContext{MaxLoad: W}
+			backward := (&PriorityBasedOptimizer{}).Optimize(pkgs, ctx)
+			forward := (&PriorityBasedOptimizer{ForwardBacktrack: true}).Optimize(pkgs, ctx)
+			if totalMass(forward) > W {
+				t.Errorf("seed %d, capacity %d: forward mass %d over capacity", seed, W, totalMass(forward))
+			}
+			if got, want := totalValue(forward), totalValue(backward); got != want {
+				t.Errorf("seed %d, capacity %d: forward value %d, backward %d", seed, W, got, want)
+			}
+		}
+	}
+
+	// two equally optimal packages: each direction takes the first it meets
+	pkgs := []PackageMetadata{
+		{Identifier: "A", MassConstraint: 5, Valuation: 10},
+		{Identifier: "B", MassConstraint: 5, Valuation: 10},
+	}
+	ctx := HeuristicContext{MaxLoad: 5}
+	if ids := identifiers((&PriorityBasedOptimizer{}).Optimize(pkgs, ctx)); len(ids) != 1 || ids[0] != "B" {
+		t.Errorf("backward selected %v, want [B]", ids)
+	}
+	if ids := identifiers((&PriorityBasedOptimizer{ForwardBacktrack: true}).Optimize(pkgs, ctx)); len(ids) != 1 || ids[0] != "A" {
+		t.Errorf("forward selected %v, want [A]", ids)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import "encoding/binary"
+
+// DeltaEncodedDP stores each DP row as varint-encoded deltas between
+// neighbouring capacities. Rows are non-decreasing in w, so the deltas are
+// small and usually fit in a single byte instead of eight.
+type DeltaEncodedDP struct {
+	rows [][]byte
+	last []int // last value written per row, for computing the next delta
+
+	// two decoded rows cover both the fill (row i-1) and backtracking (rows i, i-1)
+	cacheRow [2]int
+	cache    [2][]int
+	next     int
+}
+
+// NewDeltaEncodedDP allocates an empty delta-encoded table
+func NewDeltaEncodedDP(rows, cols int) DPTable {
+	t := &DeltaEncodedDP{
+		rows:     make([][]byte, rows),
+		last:     make([]int, rows),
+		cacheRow: [2]int{-1, -1},
+	}
+	for i := range t.rows {
+		// row 0 is all zeros and is never Set
+		t.rows[i] = make([]byte, 0, cols)
+		if i == 0 {
+			for w := 0; w < cols; w++ {
+				t.rows[i] = append(t.rows[i], 0)
+			}
+		}
+	}
+	t.cache[0] = make([]int, cols)
+	t.cache[1] = make([]int, cols)
+	return t
+}
+
+// Set appends v to row i; cells must be written in increasing w order
+func (t *DeltaEncodedDP) Set(i, w, v int) {
+	if w == 0 {
+		t.rows[i] = t.rows[i][:0]
+		t.last[i] = 0
+	}
+	t.rows[i] = binary.AppendUvarint(t.rows[i], uint64(v-t.last[i]))
+	t.last[i] = v
+	for k := range t.cacheRow {
+		if t.cacheRow[k] == i {
+			t.cacheRow[k] = -1
+		}
+	}
+}
+
+// Get decodes row i (cached) and returns cell w
+func (t *DeltaEncodedDP) Get(i, w int) int {
+	for k := range t.cacheRow {
+		if t.cacheRow[k] == i {
+			return t.cache[k][w]
+		}
+	}
+	k := t.next
+	t.next = 1 - t.next
+	row, buf := t.cache[k], t.rows[i]
+	v := 0
+	for c := range row {
+		d, n := binary.Uvarint(buf)
+		if n <= 0 {
+			break
+		}
+		v += int(d)
+		row[c] = v
+		buf = buf[n:]
+	}
+	t.cacheRow[k] = i
+	return row[w]
+}
+
+// Bytes reports the encoded size plus the two decode buffers
+func (t *DeltaEncodedDP) Bytes() int {
+	size := 2 * len(t.cache[0]) * 8
+	for _, r := range t.rows {
+		size += len(r)
+	}
+	return size
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestDeltaEncodedDPMatchesDense(t *testing.T) {
+	for seed := uint64(1); seed <= 10; seed++ {
+		pkgs := appendSynthetic(nil, 20, 100, seed)
+		dense := newDenseTable(len(pkgs)+1, 301)
+		delta := NewDeltaEncodedDP(len(pkgs)+1, 301)
+		fillTable(dense, pkgs, 300)
+		fillTable(delta, pkgs, 300)
+		for i := 0; i <= len(pkgs); i++ {
+			for w := 0; w <= 300; w++ {
+				if a, b := dense.Get(i, w), delta.Get(i, w); a != b {
+					t.Fatalf("seed %d: dp[%d][%d] = %d, delta layout has %d", seed, i, w, a, b)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkDPTableMemory fills the row and delta layouts at W=10000 and
+// reports each table's size as table-bytes
+func BenchmarkDPTableMemory(b *testing.B) {
+	const W = 10000
+	pkgs := appendSynthetic(nil, 50, W/10, 1)
+	for _, layout := range []string{"row", "delta"} {
+		b.Run(layout, func(b *testing.B) {
+			b.ReportAllocs()
+			var bytes int
+			for i := 0; i < b.N; i++ {
+				dp := dpLayouts[layout](len(pkgs)+1, W+1)
+				fillTable(dp, pkgs, W)
+				bytes = dp.Bytes()
+			}
+			b.ReportMetric(float64(bytes), "table-bytes")
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+// DensityOptimizer maximizes totalValue/totalMass of the load rather than
+// totalValue. The density of a set is a mass-weighted average of its items'
+// densities, so the optimum is typically one or a few high-ratio packages and
+// the truck is usually left mostly empty; use it only when worth per unit mass
+// matters more than total worth.
+//
+// It uses Dinkelbach's parametric search: for a candidate ratio λ it solves
+// the knapsack max Σ(v - λm) under the capacity, and raises λ to the density of
+// that solution until no selection beats λ.
+type DensityOptimizer struct{}
+
+// Optimize returns the capacity-feasible selection with the highest value density
+func (o *DensityOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	best := (&PriorityBasedOptimizer{}).Optimize(pkgs, ctx)
+	if totalMass(best) == 0 {
+		return best
+	}
+
+	for iter := 0; iter < 64; iter++ {
+		lambda := float64(totalValue(best)) / float64(totalMass(best))
+		candidate, score := parametricKnapsack(pkgs, ctx.MaxLoad, lambda)
+		if score <= 1e-9 || totalMass(candidate) == 0 {
+			break
+		}
+		best = candidate
+	}
+	return best
+}
+
+// parametricKnapsack maximizes Σ(v - λm) over selections within capacity W
+func parametricKnapsack(pkgs []PackageMetadata, W int, lambda float64) ([]PackageMetadata, float64) {
+	n := len(pkgs)
+	dp := make([][]float64, n+1)
+	for i := range dp {
+		dp[i] = make([]float64, W+1)
+	}
+	for i := 1; i <= n; i++ {
+		wt := pkgs[i-1].MassConstraint
+		gain := float64(pkgs[i-1].Valuation) - lambda*float64(wt)
+		for w := 0; w <= W; w++ {
+			dp[i][w] = dp[i-1][w]
+			if wt <= w && dp[i-1][w-wt]+gain > dp[i][w] {
+				dp[i][w] = dp[i-1][w-wt] + gain
+			}
+		}
+	}
+
+	var res []PackageMetadata
+	w := W
+	for i := n; i > 0; i-- {
+		if dp[i][w] != dp[i-1][w] {
+			res = append(res, pkgs[i-1])
+			w -= pkgs[i-1].MassConstraint
+		}
+	}
+	return res, dp[n][W]
+}
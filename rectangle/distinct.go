@@ -0,0 +1,75 @@
+package main
+
+import "sort"
+
+// DistinctMassOptimizer maximizes value using at most K distinct mass values
+// among the selected packages. Packages sharing a mass form a group; taking j
+// packages from a group always means its j most valuable ones, so the DP runs
+// over groups with state (distinct masses used, capacity) and tries every j.
+// Time is O(K·n·W) and memory O(groups·K·W) for the backtracking choices.
+type DistinctMassOptimizer struct {
+	K int
+}
+
+// massGroup is the packages with one mass value, most valuable first
+type massGroup struct {
+	mass   int
+	pkgs   []PackageMetadata
+	prefix []int // prefix[j] = value of the j most valuable packages
+}
+
+// Optimize returns the best selection using at most K distinct masses
+func (o *DistinctMassOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	W, K := ctx.MaxLoad, o.K
+	if K <= 0 {
+		return []PackageMetadata{}
+	}
+
+	byMass := make(map[int][]PackageMetadata)
+	for _, p := range pkgs {
+		byMass[p.MassConstraint] = append(byMass[p.MassConstraint], p)
+	}
+	groups := make([]massGroup, 0, len(byMass))
+	for mass, members := range byMass {
+		sort.SliceStable(members, func(i, j int) bool { return members[i].Valuation > members[j].Valuation })
+		prefix := make([]int, len(members)+1)
+		for j, p := range members {
+			prefix[j+1] = prefix[j] + p.Valuation
+		}
+		groups = append(groups, massGroup{mass: mass, pkgs: members, prefix: prefix})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].mass < groups[j].mass })
+
+	// dp[k][w]: best value with at most k distinct masses and capacity w;
+	// choice[g][k][w]: how many packages group g contributes at that state
+	dp := make([][]int, K+1)
+	for k := range dp {
+		dp[k] = make([]int, W+1)
+	}
+	choice := make([][][]int, len(groups))
+	for g, grp := range groups {
+		choice[g] = make([][]int, K+1)
+		for k := K; k >= 1; k-- {
+			choice[g][k] = make([]int, W+1)
+			for w := W; w >= 0; w-- {
+				for j := 1; j < len(grp.prefix) && j*grp.mass <= w; j++ {
+					if c := dp[k-1][w-j*grp.mass] + grp.prefix[j]; c > dp[k][w] {
+						dp[k][w] = c
+						choice[g][k][w] = j
+					}
+				}
+			}
+		}
+	}
+
+	res := []PackageMetadata{}
+	k, w := K, W
+	for g := len(groups) - 1; g >= 0 && k > 0; g-- {
+		if j := choice[g][k][w]; j > 0 {
+			res = append(res, groups[g].pkgs[:j]...)
+			w -= j * groups[g].mass
+			k--
+		}
+	}
+	return res
+}
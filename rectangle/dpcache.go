@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// dpCacheKey identifies a DP table by the ordered package list and capacity
+func dpCacheKey(pkgs []PackageMetadata, W int) string {
+	h := sha256.New()
+	var buf [8]byte
+	for _, p := range pkgs {
+		h.Write([]byte(p.Identifier))
+		h.Write([]byte{0})
+		binary.LittleEndian.PutUint64(buf[:], uint64(p.MassConstraint))
+		h.Write(buf[:])
+		binary.LittleEndian.PutUint64(buf[:], uint64(p.Valuation))
+		h.Write(buf[:])
+	}
+	binary.LittleEndian.PutUint64(buf[:], uint64(W))
+	h.Write(buf[:])
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CachedDPOptimizer runs the standard DP but persists filled tables to a gob
+// file keyed by package-list hash, reusing them on later runs. The cache is
+// ignored when it is older than the running binary, since a rebuilt binary
+// may fill tables differently. One instance may be shared by concurrent
+// callers, such as the -sweep workers.
+type CachedDPOptimizer struct {
+	Path string
+
+	mu       sync.Mutex
+	tables   map[string][][]int // loaded on first use and kept for batch runs
+	counters DPCounters         // of the last Optimize; a cache hit computes no cells
+}
+
+// Optimize loads the DP table from the cache when possible, otherwise fills and stores it
+func (o *CachedDPOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	key := dpCacheKey(pkgs, ctx.MaxLoad)
+	o.mu.Lock()
+	if o.tables == nil {
+		o.tables = o.load()
+	}
+	dp, ok := o.tables[key]
+	o.mu.Unlock()
+	if ok {
+		verbosef("dp cache: hit %s", key[:12])
+		return o.backtrack(dp, pkgs, ctx.MaxLoad, 0)
+	}
+
+	// fill outside the lock so that other callers can hit meanwhile
+	table := newDenseTable(len(pkgs)+1, ctx.MaxLoad+1)
+	cells := fillTable(table, pkgs, ctx.MaxLoad)
+	dp = table.(denseTable)
+	o.mu.Lock()
+	o.tables[key] = dp
+	err := o.save(o.tables)
+	o.mu.Unlock()
+	if err != nil {
+		warnf("dp cache: %v", err)
+	}
+	return o.backtrack(dp, pkgs, ctx.MaxLoad, cells)
+}
+
+// backtrack recovers the selection from dp and records the run's counters
+func (o *CachedDPOptimizer) backtrack(dp [][]int, pkgs []PackageMetadata, W, cells int) []PackageMetadata {
+	selected, steps := backtrackCounted(denseTable(dp), pkgs, W)
+	o.mu.Lock()
+	o.counters = DPCounters{CellsComputed: cells, BacktrackSteps: steps}
+	o.mu.Unlock()
+	return selected
+}
+
+// Work returns the counters of the last Optimize
+func (o *CachedDPOptimizer) Work() DPCounters {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.counters
+}
+
+// load returns the cached tables, or an empty set if the cache is missing, stale or unreadable
+func (o *CachedDPOptimizer) load() map[string][][]int {
+	tables := make(map[string][][]int)
+	info, err := os.Stat(o.Path)
+	if err != nil {
+		return tables
+	}
+	if exe, err := os.Executable(); err == nil {
+		if bin, err := os.Stat(exe); err == nil && info.ModTime().Before(bin.ModTime()) {
+			verbosef("dp cache: %s is older than the binary, ignoring", o.Path)
+			return tables
+		}
+	}
+
+	f, err := os.Open(o.Path)
+	if err != nil {
+		return tables
+	}
+	defer f.Close()
+	if err := gob.NewDecoder(f).Decode(&tables); err != nil {
+		warnf("dp cache: %s unreadable, rebuilding: %v", o.Path, err)
+		return make(map[string][][]int)
+	}
+	return tables
+}
+
+// save writes the tables to a temporary file and renames it over Path, so
+// that a concurrent reader sees either the old cache or the new one
+func (o *CachedDPOptimizer) save(tables map[string][][]int) error {
+	f, err := os.CreateTemp(filepath.Dir(o.Path), filepath.Base(o.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // fails harmlessly once renamed
+	if err := gob.NewEncoder(f).Encode(tables); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), o.Path)
+}
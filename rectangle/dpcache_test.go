@@ -0,0 +1,41 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestCachedDPConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dp.gob")
+	pkgs := NewEmailBasedPackageGenerator().Generate("test@example.com")
+	shared := &CachedDPOptimizer{Path: path}
+	var wg sync.WaitGroup
+	for k := 0; k < 8; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			for w := k; w < 200; w += 8 {
+				shared.Optimize(pkgs, HeuristicContext{MaxLoad: w, PriorityFactor: 1.0})
+				// a second process reading the file mid-sweep sees a whole cache
+				if tables := (&CachedDPOptimizer{Path: path}).load(); len(tables) == 0 {
+					t.Errorf("capacity %d: cache file unreadable after a save", w)
+				}
+			}
+		}(k)
+	}
+	wg.Wait()
+
+	reloaded := &CachedDPOptimizer{Path: path}
+	if tables := reloaded.load(); len(tables) != 200 {
+		t.Fatalf("cache holds %d tables, want 200", len(tables))
+	}
+	dp := &PriorityBasedOptimizer{}
+	for _, w := range []int{0, 37, 50, 199} {
+		ctx := HeuristicContext{MaxLoad: w, PriorityFactor: 1.0}
+		if got, want := identifiers(reloaded.Optimize(pkgs, ctx)), identifiers(dp.Optimize(pkgs, ctx)); !slices.Equal(got, want) {
+			t.Errorf("capacity %d: cached %v, want %v", w, got, want)
+		}
+	}
+}
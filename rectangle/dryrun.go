@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// constrainedOptimizers lists the flags that replace the -algo optimizer
+// with a DP of their own
+func (o *options) constrainedOptimizers() []string {
+	var flags []string
+	if o.objective == "density" {
+		flags = append(flags, "-objective density")
+	}
+	if o.costBudget > 0 {
+		flags = append(flags, "-total-cost-constraint")
+	}
+	if o.lightestOptimum {
+		flags = append(flags, "-weight-class-priority")
+	}
+	if o.roundDivisor > 0 {
+		flags = append(flags, "-round-divisor")
+	}
+	if o.targetValue > 0 {
+		flags = append(flags, "-target-value")
+	}
+	if len(o.groupBonus) > 0 {
+		flags = append(flags, "-group-bonus")
+	}
+	if len(o.chooseOne) > 0 {
+		flags = append(flags, "-choose-one")
+	}
+	if o.maxDistinctMass > 0 {
+		flags = append(flags, "-max-distinct-masses")
+	}
+	if o.massParity != "" {
+		flags = append(flags, "-mass-parity")
+	}
+	return flags
+}
+
+// conflicts reports flag combinations that would be silently ignored
+func (o *options) conflicts() error {
+	constrained := o.constrainedOptimizers()
+	switch {
+	case len(constrained) > 0 && o.algo != "dp":
+		return fmt.Errorf("%s runs its own DP and cannot be combined with -algo %s", constrained[0], o.algo)
+	case len(constrained) > 1:
+		return fmt.Errorf("%s and %s each replace the optimizer and are mutually exclusive", constrained[0], constrained[1])
+	case len(constrained) > 0 && o.sandbox:
+		return fmt.Errorf("%s cannot run in the -sandbox child, which only runs -algo", constrained[0])
+	case o.policy != "" && len(constrained) > 0:
+		return fmt.Errorf("-policy searches unconstrained selections and cannot be combined with %s", constrained[0])
+	case o.policy != "" && (len(o.forceInclude) > 0 || o.valueCap > 0 || o.randomizeIDs != 0):
+		return errors.New("-policy cannot be combined with -force-include, -package-value-cap or -randomize-identifiers")
+	case o.policy != "" && o.batch != "":
+		return errors.New("-policy and -batch are mutually exclusive")
+	case o.submitURL != "" && o.batch == "" && !o.simulateChallenge:
+		return errors.New("-submit-url requires -batch or -simulate-challenge")
+	case o.simulateChallenge && o.submitURL == "":
+		return errors.New("-simulate-challenge requires -submit-url")
+	case o.stdinPassword && !o.simulateChallenge:
+		return errors.New("-stdin-password requires -simulate-challenge")
+	case o.metricsFile != "" && o.batch == "":
+		return errors.New("-metrics-file requires -batch")
+	case o.robust && o.batch != "":
+		return errors.New("-robust and -batch are mutually exclusive")
+	case o.verboseJSON && o.quiet:
+		return errors.New("-verbose-json and -quiet are mutually exclusive")
+	case o.verboseJSON && o.format != "json":
+		return fmt.Errorf("-verbose-json writes JSON and cannot be combined with -format %s", o.format)
+	case o.updateTestCase && o.testCase == "":
+		return errors.New("-update-test-case requires -generate-test-case")
+	case o.randomizeIDs != 0 && (len(o.chooseOne) > 0 || len(o.groupBonus) > 0):
+		return errors.New("-randomize-identifiers cannot hide identifiers that -choose-one or -group-bonus refer to")
+	case o.earlyTermination && o.targetValue <= 0:
+		return errors.New("-early-termination requires -target-value")
+	case o.lookahead > 0 && o.algo != "greedy":
+		return errors.New("-weight-lookahead requires -algo greedy")
+	case o.solverTimeoutMS > 0 && o.timeout > 0:
+		return errors.New("-solver-timeout-ms and -timeout are mutually exclusive")
+	case o.timeoutRetry && o.timeout <= 0 && o.solverTimeoutMS <= 0:
+		return errors.New("-timeout-retry requires -timeout or -solver-timeout-ms")
+	case o.timeoutSignal && o.timeout <= 0 && o.solverTimeoutMS <= 0:
+		return errors.New("-timeout-signal requires -timeout or -solver-timeout-ms")
+	case o.circuitBreaker && (o.batch == "" || o.algo != "dp"):
+		return errors.New("-circuit-breaker requires -batch and -algo dp")
+	case o.benchmarkDP && o.algo == "dp":
+		return errors.New("-benchmark-against-dp compares a heuristic -algo with the DP; it has nothing to compare for -algo dp")
+	case (o.resultHash || o.verifyResult != "") && o.resultSignKey == "":
+		return errors.New("-result-hash and -verify-result-hash require -result-sign-key")
+	case o.resultHash && (o.format != "text" || o.emitTestcase):
+		return errors.New("-result-hash signs the text result; it cannot be combined with -format or -emit-testcase")
+	case o.strict && !o.logMissing:
+		return errors.New("-strict requires -log-missing-packages")
+	case o.generateN > 0 && (o.catalog != "" || o.inlinePackages != "" || o.gitCatalog != ""):
+		return errors.New("-generate-n only applies to generated catalogs, not -catalog, -packages or -catalog-from-git")
+	case (o.noX || o.noY || o.onlyDynamic) && (o.catalog != "" || o.inlinePackages != "" || o.gitCatalog != ""):
+		return errors.New("-no-x-package, -no-y-package and -only-dynamic only apply to generated catalogs")
+	case o.gitCatalog != "" && (o.catalog != "" || o.inlinePackages != ""):
+		return errors.New("-catalog-from-git cannot be combined with -catalog or -packages")
+	case o.catalog != "" && o.inlinePackages != "":
+		return errors.New("-catalog and -packages are mutually exclusive")
+	}
+	return nil
+}
+
+// dryRun performs the loading and validation a real run would, without
+// optimizing, and reports whether the configuration is usable
+func dryRun(opts *options) int {
+	if err := validateRun(opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println("configuration valid")
+	return 0
+}
+
+// validateRun loads the inputs the selected mode needs and checks them
+func validateRun(opts *options) error {
+	if opts.batch != "" {
+		_, err := readEmails(opts.batch)
+		return err
+	}
+	if opts.robust || opts.selfCheck || opts.jsonSchema || opts.seedCollisions > 0 {
+		return nil
+	}
+
+	packages, err := loadPackages(opts)
+	if err != nil {
+		return err
+	}
+	if packages, err = preprocess(opts, packages); err != nil {
+		return err
+	}
+	if opts.sweep != "" {
+		if _, _, err := parseSweep(opts.sweep); err != nil {
+			return err
+		}
+	}
+	if opts.algo == "dp" {
+		return checkMemoryLimit(len(packages)+1, opts.capacity, opts.memoryLimit)
+	}
+	return nil
+}
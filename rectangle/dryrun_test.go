@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConstrainedOptimizerConflicts(t *testing.T) {
+	constrained := [][]string{
+		{"-objective", "density"},
+		{"-total-cost-constraint", "20"},
+		{"-weight-class-priority"},
+		{"-round-divisor", "5"},
+		{"-target-value", "100"},
+		{"-group-bonus", "G:10:A,B"},
+		{"-choose-one", "A,B"},
+		{"-max-distinct-masses", "2"},
+		{"-mass-parity", "even"},
+	}
+	for _, c := range constrained {
+		if _, err := parseOptions(append(append([]string{}, c...), "test@example.com")); err != nil {
+			t.Errorf("%v alone: %v", c, err)
+		}
+		args := append(append([]string{"-sandbox"}, c...), "test@example.com")
+		if _, err := parseOptions(args); err == nil || !strings.Contains(err.Error(), "-sandbox") {
+			t.Errorf("%v: error = %v, want a conflict with -sandbox", args, err)
+		}
+		for _, algo := range []string{"greedy", "sa", "lazy"} {
+			args := append(append([]string{"-algo", algo}, c...), "test@example.com")
+			if _, err := parseOptions(args); err == nil || !strings.Contains(err.Error(), "-algo "+algo) {
+				t.Errorf("%v: error = %v, want a conflict with -algo %s", args, err, algo)
+			}
+		}
+	}
+}
+
+func TestConstrainedOptimizersMutuallyExclusive(t *testing.T) {
+	for _, args := range [][]string{
+		{"-objective", "density", "-mass-parity", "odd"},
+		{"-total-cost-constraint", "20", "-target-value", "100"},
+		{"-choose-one", "A,B", "-group-bonus", "G:10:A,B"},
+		{"-weight-class-priority", "-round-divisor", "5"},
+		{"-max-distinct-masses", "2", "-mass-parity", "even"},
+	} {
+		if _, err := parseOptions(append(args, "test@example.com")); err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+			t.Errorf("%v: error = %v, want mutually exclusive", args, err)
+		}
+	}
+}
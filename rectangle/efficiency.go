@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// packageEfficiency is a selected package's share of the loaded value divided
+// by its share of the loaded mass: (v/V) / (m/M) for selection totals V and M.
+// 1 means the package pulls exactly its weight, above 1 it carries more value
+// than its mass would suggest. Massless packages are +Inf when they add value
+// and NaN when they add nothing (or the whole selection is massless/valueless).
+func packageEfficiency(p PackageMetadata, totalMass, totalValue int) float64 {
+	if totalValue == 0 || totalMass == 0 {
+		return math.NaN()
+	}
+	valueShare := float64(p.Valuation) / float64(totalValue)
+	if p.MassConstraint == 0 {
+		if valueShare == 0 {
+			return math.NaN()
+		}
+		return math.Inf(1)
+	}
+	return valueShare / (float64(p.MassConstraint) / float64(totalMass))
+}
+
+// printEfficiency writes the efficiency of each selected package
+func printEfficiency(w io.Writer, selected []PackageMetadata) {
+	if len(selected) == 0 {
+		fmt.Fprintln(w, "efficiency: no packages selected")
+		return
+	}
+	m, v := totalMass(selected), totalValue(selected)
+	fmt.Fprintf(w, "%-4s %6s %6s %8s %8s %10s\n", "item", "mass", "value", "mass %", "value %", "efficiency")
+	for _, p := range selected {
+		massPct, valuePct := 0.0, 0.0
+		if m > 0 {
+			massPct = 100 * float64(p.MassConstraint) / float64(m)
+		}
+		if v > 0 {
+			valuePct = 100 * float64(p.Valuation) / float64(v)
+		}
+		eff := packageEfficiency(p, m, v)
+		effText := fnum(eff, 3)
+		switch {
+		case math.IsNaN(eff):
+			effText = "n/a"
+		case math.IsInf(eff, 1):
+			effText = "inf"
+		}
+		fmt.Fprintf(w, "%-4s %6d %6d %8.1f %8.1f %10s\n", p.Identifier, p.MassConstraint, p.Valuation, massPct, valuePct, effText)
+	}
+}
+
+// printWeightUtilization writes each selected package's share of the selected
+// mass, marking the heaviest contributor
+func printWeightUtilization(w io.Writer, selected []PackageMetadata) {
+	m := totalMass(selected)
+	if len(selected) == 0 || m == 0 {
+		fmt.Fprintln(w, "weight utilization: no mass selected")
+		return
+	}
+	heaviest := 0
+	for _, p := range selected {
+		heaviest = max(heaviest, p.MassConstraint)
+	}
+	for _, p := range selected {
+		marker := ""
+		if p.MassConstraint == heaviest {
+			marker = "  <- heaviest"
+		}
+		fmt.Fprintf(w, "%s: %s/%s = %s%%%s\n", p.Identifier, num(p.MassConstraint), num(m), fnum(100*float64(p.MassConstraint)/float64(m), 1), marker)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// cloudEvent is a CloudEvents 1.0 envelope in structured JSON mode
+type cloudEvent struct {
+	SpecVersion     string `json:"specversion"`
+	ID              string `json:"id"`
+	Source          string `json:"source"`
+	Type            string `json:"type"`
+	Time            string `json:"time"`
+	DataContentType string `json:"datacontenttype"`
+	TraceID         string `json:"traceid"`
+	Data            any    `json:"data"`
+}
+
+// eventEmitter writes one CloudEvent per line; a nil emitter discards events
+type eventEmitter struct {
+	w       io.Writer
+	traceID string
+	seq     int
+}
+
+// newEventEmitter derives the trace ID from a hash of the email so every
+// event of one run, and of reruns for the same email, correlate
+func newEventEmitter(w io.Writer, email string) *eventEmitter {
+	sum := sha256.Sum256([]byte(email))
+	return &eventEmitter{w: w, traceID: hex.EncodeToString(sum[:16])}
+}
+
+// emit writes an event of the given type carrying data
+func (e *eventEmitter) emit(eventType string, data any) {
+	if e == nil {
+		return
+	}
+	e.seq++
+	line, err := json.Marshal(cloudEvent{
+		SpecVersion:     "1.0",
+		ID:              fmt.Sprintf("%s-%d", e.traceID, e.seq),
+		Source:          "/rectangle",
+		Type:            eventType,
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		DataContentType: "application/json",
+		TraceID:         e.traceID,
+		Data:            data,
+	})
+	if err != nil {
+		warnf("emit %s: %v", eventType, err)
+		return
+	}
+	fmt.Fprintf(e.w, "%s\n", line)
+}
+
+// packageEvent is the payload of PackageSelected and PackageExcluded
+type packageEvent struct {
+	Identifier string `json:"identifier"`
+	Mass       int    `json:"mass"`
+	Value      int    `json:"value"`
+}
+
+// emitSelection publishes one event per package and the completion summary
+func (e *eventEmitter) emitSelection(pkgs, selected []PackageMetadata) {
+	if e == nil {
+		return
+	}
+	chosen := make(map[string]bool, len(selected))
+	for _, p := range selected {
+		chosen[p.Identifier] = true
+	}
+	for _, p := range pkgs {
+		eventType := "PackageExcluded"
+		if chosen[p.Identifier] {
+			eventType = "PackageSelected"
+		}
+		e.emit(eventType, packageEvent{Identifier: p.Identifier, Mass: p.MassConstraint, Value: p.Valuation})
+	}
+	e.emit("OptimizationCompleted", map[string]int{
+		"selected":    len(selected),
+		"total_mass":  totalMass(selected),
+		"total_value": totalValue(selected),
+	})
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// preprocess applies the catalog filters requested on the command line and
+// rejects invalid catalogs (when asked) and forced selections that cannot fit
+func preprocess(opts *options, pkgs []PackageMetadata) ([]PackageMetadata, error) {
+	if opts.uniqueIDs {
+		if err := CheckUniqueIdentifiers(pkgs); err != nil {
+			return nil, err
+		}
+	}
+	if opts.strictTypes {
+		if err := CheckStrictTypes(pkgs); err != nil {
+			return nil, err
+		}
+	}
+	if opts.weightFloor > 0 {
+		pkgs = applyWeightFloor(pkgs, opts.weightFloor)
+	}
+	if opts.valueFloor > 0 {
+		pkgs = applyValueFloor(pkgs, opts.valueFloor)
+	}
+	forced := opts.forceInclude
+	if opts.logMissing {
+		if missing := FindMissingIdentifiers(opts.referencedIDs(), pkgs); len(missing) > 0 {
+			if opts.strict {
+				return nil, fmt.Errorf("packages not in the catalog: %s", strings.Join(missing, ","))
+			}
+			warnf("packages not in the catalog (ignored): %s", strings.Join(missing, ","))
+			// ForcedOptimizer skips identifiers it cannot find; only the fit check needs trimming
+			forced = slices.DeleteFunc(slices.Clone(forced), func(id string) bool { return slices.Contains(missing, id) })
+		}
+	}
+	if len(forced) > 0 {
+		limit, _ := opts.loadLimit()
+		if err := checkForced(pkgs, forced, limit); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.categoryCaps) > 0 {
+		pkgs = applyCategoryCaps(pkgs, opts.categoryCaps)
+	}
+	if opts.minDensity > 0 {
+		pkgs = filterMinDensity(pkgs, opts.minDensity)
+	}
+	if opts.topK > 0 {
+		pkgs = topKByValue(pkgs, opts.topK)
+	}
+	if opts.maxDPRows > 0 && len(pkgs) > opts.maxDPRows {
+		warnf("-max-dp-rows %d excludes packages: %s", opts.maxDPRows, strings.Join(identifiers(pkgs[opts.maxDPRows:]), ","))
+		pkgs = pkgs[:opts.maxDPRows]
+	}
+	return pkgs, nil
+}
+
+// applyWeightFloor raises every mass below floor to floor, so even a
+// massless package takes up one minimum load unit
+func applyWeightFloor(pkgs []PackageMetadata, floor int) []PackageMetadata {
+	out := make([]PackageMetadata, len(pkgs))
+	for i, p := range pkgs {
+		if p.MassConstraint < floor {
+			verbosef("package %s: mass %s raised to floor %s", p.Identifier, num(p.MassConstraint), num(floor))
+			p.MassConstraint = floor
+		}
+		out[i] = p
+	}
+	return out
+}
+
+// applyValueFloor raises every value below floor to floor, warning for each
+func applyValueFloor(pkgs []PackageMetadata, floor int) []PackageMetadata {
+	out := make([]PackageMetadata, len(pkgs))
+	for i, p := range pkgs {
+		if p.Valuation < floor {
+			warnf("package %s: value %s raised to floor %s", p.Identifier, num(p.Valuation), num(floor))
+			p.Valuation = floor
+		}
+		out[i] = p
+	}
+	return out
+}
+
+// selectDynamic applies -no-x-package, -no-y-package and -only-dynamic to a
+// generated catalog whose first base packages are the fixed ones
+func selectDynamic(pkgs []PackageMetadata, base int, noX, noY, onlyDynamic bool) []PackageMetadata {
+	out := make([]PackageMetadata, 0, len(pkgs))
+	for i, p := range pkgs {
+		switch {
+		case onlyDynamic && i < base:
+		case noX && i >= base && p.Identifier == "X":
+		case noY && i >= base && p.Identifier == "Y":
+		default:
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// filterMinDensity drops packages whose value per unit mass is below r;
+// massless packages are always kept. Like topKByValue this can discard a
+// low-density package that the unfiltered optimum uses to fill spare capacity.
+func filterMinDensity(pkgs []PackageMetadata, r float64) []PackageMetadata {
+	out := make([]PackageMetadata, 0, len(pkgs))
+	for _, p := range pkgs {
+		if p.MassConstraint == 0 || float64(p.Valuation) >= r*float64(p.MassConstraint) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// topKByValue keeps the k highest-valued packages in their original order.
+// This is a crude approximation: it can discard light, modestly valued
+// packages that belong to the true optimum.
+func topKByValue(pkgs []PackageMetadata, k int) []PackageMetadata {
+	if k >= len(pkgs) {
+		return pkgs
+	}
+	order := make([]int, len(pkgs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return pkgs[order[a]].Valuation > pkgs[order[b]].Valuation
+	})
+	keep := make([]bool, len(pkgs))
+	for _, i := range order[:k] {
+		keep[i] = true
+	}
+
+	out := make([]PackageMetadata, 0, k)
+	for i, p := range pkgs {
+		if keep[i] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValueFloorChangesSelection(t *testing.T) {
+	pkgs := []PackageMetadata{
+		{Identifier: "A", MassConstraint: 10, Valuation: 100},
+		{Identifier: "B", MassConstraint: 5, Valuation: 1},
+		{Identifier: "C", MassConstraint: 5, Valuation: 40},
+	}
+	var diag bytes.Buffer
+	level, out := diagLevel, diagOut
+	diagLevel, diagOut = levelNormal, &diag
+	t.Cleanup(func() { diagLevel, diagOut = level, out })
+
+	ctx := HeuristicContext{MaxLoad: 10}
+	tests := []struct {
+		floor int
+		want  []string
+	}{
+		{floor: 0, want: []string{"A"}},
+		{floor: 45, want: []string{"A"}},      // B+C 90 < 100
+		{floor: 60, want: []string{"B", "C"}}, // B+C 120 > 100
+	}
+	for _, tt := range tests {
+		floored := pkgs
+		if tt.floor > 0 {
+			floored = applyValueFloor(pkgs, tt.floor)
+		}
+		ids := identifiers((&PriorityBasedOptimizer{}).Optimize(floored, ctx))
+		sort.Strings(ids)
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("floor %d: selected %v, want %v", tt.floor, ids, tt.want)
+		}
+	}
+	if pkgs[1].Valuation != 1 {
+		t.Errorf("applyValueFloor modified its input")
+	}
+
+	// floors 45 and 60 each raise B and C and warn with both values
+	got := diag.String()
+	for _, want := range []string{"package B: value 1 raised to floor 45", "package C: value 40 raised to floor 60"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("warnings %q missing %q", got, want)
+		}
+	}
+	if n := strings.Count(got, "\n"); n != 4 {
+		t.Errorf("%d warnings, want 4", n)
+	}
+}
+
+func TestWeightFloorRaisesZeroMass(t *testing.T) {
+	pkgs := []PackageMetadata{
+		{Identifier: "A", MassConstraint: 0, Valuation: 50},
+		{Identifier: "B", MassConstraint: 8, Valuation: 60},
+	}
+	var diag bytes.Buffer
+	level, out := diagLevel, diagOut
+	diagLevel, diagOut = levelVerbose, &diag
+	t.Cleanup(func() { diagLevel, diagOut = level, out })
+
+	floored := applyWeightFloor(pkgs, 3)
+	if floored[0].MassConstraint != 3 || floored[1].MassConstraint != 8 {
+		t.Errorf("floored masses %d, %d, want 3, 8", floored[0].MassConstraint, floored[1].MassConstraint)
+	}
+	if pkgs[0].MassConstraint != 0 {
+		t.Errorf("applyWeightFloor modified its input")
+	}
+	if got := diag.String(); !strings.Contains(got, "package A: mass 0 raised to floor 3") || strings.Contains(got, "package B") {
+		t.Errorf("verbose output %q, want only A's original mass", got)
+	}
+
+	// at capacity 10 the free A fits beside B; weighing 3 it no longer does
+	ctx := HeuristicContext{MaxLoad: 10}
+	if ids := identifiers((&PriorityBasedOptimizer{}).Optimize(pkgs, ctx)); len(ids) != 2 {
+		t.Errorf("unfloored: selected %v, want A and B", ids)
+	}
+	if ids := identifiers((&PriorityBasedOptimizer{}).Optimize(floored, ctx)); !slices.Equal(ids, []string{"B"}) {
+		t.Errorf("floored: selected %v, want [B]", ids)
+	}
+}
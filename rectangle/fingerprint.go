@@ -0,0 +1,28 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CatalogFingerprint returns the hex SHA-256 of the packages sorted by
+// identifier, mass and value, one "identifier:mass:value" line each, so the
+// same catalog hashes the same whatever order it was generated in
+func CatalogFingerprint(pkgs []PackageMetadata) string {
+	lines := make([]string, len(pkgs))
+	for i, p := range pkgs {
+		lines[i] = fmt.Sprintf("%s:%d:%d\n", p.Identifier, p.MassConstraint, p.Valuation)
+	}
+	slices.Sort(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+// PackageSetHash is the first 8 hex characters of CatalogFingerprint, short
+// enough for cache keys and audit log lines
+func PackageSetHash(pkgs []PackageMetadata) string {
+	return CatalogFingerprint(pkgs)[:8]
+}
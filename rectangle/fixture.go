@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// testFixture is a golden file: the catalog for an email and its optimum
+type testFixture struct {
+	Email      string            `json:"email"`
+	Capacity   int               `json:"capacity"`
+	Packages   []PackageMetadata `json:"packages"`
+	Selected   []string          `json:"selected"`
+	TotalMass  int               `json:"total_mass"`
+	TotalValue int               `json:"total_value"`
+}
+
+// fixturePath returns testdata/<name>.json, rejecting names that would
+// escape the testdata directory
+func fixturePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid test case name %q", name)
+	}
+	return filepath.Join("testdata", name+".json"), nil
+}
+
+// writeTestFixture writes the fixture for result and pkgs; an existing
+// fixture is only replaced when update is set
+func writeTestFixture(name string, pkgs []PackageMetadata, result OptimizationResult, update bool) (string, error) {
+	path, err := fixturePath(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil && !update {
+		return "", fmt.Errorf("%s already exists; use -update-test-case to regenerate it", path)
+	}
+	data, err := json.MarshalIndent(testFixture{
+		Email:      result.Email,
+		Capacity:   result.Capacity,
+		Packages:   pkgs,
+		Selected:   result.Selected,
+		TotalMass:  result.TotalMass,
+		TotalValue: result.TotalValue,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0o644)
+}
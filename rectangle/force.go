@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrForcedPackagesInfeasible reports that the packages forced into the load
+// already weigh more than the capacity allows
+type ErrForcedPackagesInfeasible struct {
+	ForcedPackages    []string
+	TotalForcedWeight int
+	Capacity          int
+
+	masses map[string]int
+}
+
+func (e *ErrForcedPackagesInfeasible) Error() string {
+	return fmt.Sprintf("forced packages %s weigh %d, exceeding capacity %d; %s",
+		strings.Join(e.ForcedPackages, ","), e.TotalForcedWeight, e.Capacity, e.Suggestions())
+}
+
+// Suggestions lists the fewest forced packages whose removal makes the rest
+// fit; dropping the heaviest first minimizes the count
+func (e *ErrForcedPackagesInfeasible) Suggestions() string {
+	ids := append([]string(nil), e.ForcedPackages...)
+	sort.SliceStable(ids, func(i, j int) bool { return e.masses[ids[i]] > e.masses[ids[j]] })
+
+	var drop []string
+	weight := e.TotalForcedWeight
+	for _, id := range ids {
+		if weight <= e.Capacity {
+			break
+		}
+		drop = append(drop, id)
+		weight -= e.masses[id]
+	}
+	return fmt.Sprintf("remove %s from the forced set (remaining weight %d)", strings.Join(drop, ","), weight)
+}
+
+// parseForceInclude splits a comma-separated list of package identifiers
+func parseForceInclude(s string) []string {
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// checkForced verifies every forced package exists and that together they fit
+func checkForced(pkgs []PackageMetadata, forced []string, capacity int) error {
+	masses := make(map[string]int, len(pkgs))
+	for _, p := range pkgs {
+		masses[p.Identifier] = p.MassConstraint
+	}
+	total := 0
+	for _, id := range forced {
+		mass, ok := masses[id]
+		if !ok {
+			return fmt.Errorf("forced package %q is not in the catalog", id)
+		}
+		total += mass
+	}
+	if total > capacity {
+		return &ErrForcedPackagesInfeasible{ForcedPackages: forced, TotalForcedWeight: total, Capacity: capacity, masses: masses}
+	}
+	return nil
+}
+
+// FindMissingIdentifiers returns the required identifiers that no package
+// has, in the order given and without repeats
+func FindMissingIdentifiers(required []string, pkgs []PackageMetadata) []string {
+	present := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		present[p.Identifier] = true
+	}
+	var missing []string
+	for _, id := range required {
+		if !present[id] {
+			missing = append(missing, id)
+			present[id] = true // report once
+		}
+	}
+	return missing
+}
+
+// referencedIDs lists the package identifiers named by -force-include,
+// -choose-one and -group-bonus
+func (o *options) referencedIDs() []string {
+	ids := append([]string(nil), o.forceInclude...)
+	for _, group := range o.chooseOne {
+		ids = append(ids, group...)
+	}
+	for _, g := range o.groupBonus {
+		ids = append(ids, g.Members...)
+	}
+	return ids
+}
+
+// ForcedOptimizer always loads the forced packages and lets Inner fill the
+// capacity they leave over from the remaining catalog. When the forced
+// packages alone exceed the capacity, Optimize returns nil and leaves an
+// *ErrForcedPackagesInfeasible in Err.
+type ForcedOptimizer struct {
+	Inner  LoadOptimizer
+	Forced []string
+	Err    error
+	// Rest is Inner's selection from the remaining catalog in the last
+	// Optimize call; it stays nil when Inner found no feasible selection
+	Rest []PackageMetadata
+}
+
+// Optimize returns the forced packages plus Inner's selection from the rest
+func (o *ForcedOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	o.Err, o.Rest = nil, nil
+	isForced := make(map[string]bool, len(o.Forced))
+	for _, id := range o.Forced {
+		isForced[id] = true
+	}
+	var forced, rest []PackageMetadata
+	for _, p := range pkgs {
+		if isForced[p.Identifier] {
+			forced = append(forced, p)
+		} else {
+			rest = append(rest, p)
+		}
+	}
+	if weight := totalMass(forced); weight > ctx.MaxLoad {
+		masses := make(map[string]int, len(forced))
+		for _, p := range forced {
+			masses[p.Identifier] = p.MassConstraint
+		}
+		o.Err = &ErrForcedPackagesInfeasible{ForcedPackages: identifiers(forced), TotalForcedWeight: weight, Capacity: ctx.MaxLoad, masses: masses}
+		return nil
+	}
+	ctx.MaxLoad -= totalMass(forced)
+	// Inner may find nothing else that fits; the forced packages still load
+	o.Rest = o.Inner.Optimize(rest, ctx)
+	return append(forced, o.Rest...)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestReserveLeavesSpaceEmpty(t *testing.T) {
+	const capacity = 60
+	for _, reserve := range []int{1, 10, 30, 59} {
+		opts, err := parseOptions([]string{"-capacity", strconv.Itoa(capacity), "-reserve", strconv.Itoa(reserve), "test@example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		limit, _ := opts.loadLimit()
+		for seed := uint64(1); seed <= 10; seed++ {
+			pkgs := appendSynthetic(nil, 15, capacity/2, seed)
+			for name, newOptimizer := range optimizers {
+				selected := newOptimizer(opts).Optimize(pkgs, HeuristicContext{MaxLoad: limit, PriorityFactor: 1.0})
+				if mass := totalMass(selected); mass > capacity-reserve {
+					t.Errorf("reserve %d, seed %d, %s: mass %d intrudes on the reserved space", reserve, seed, name, mass)
+				}
+			}
+		}
+	}
+}
+
+func TestReserveRejectsFullCapacity(t *testing.T) {
+	if _, err := parseOptions([]string{"-capacity", "50", "-reserve", "50", "test@example.com"}); err == nil {
+		t.Error("reserve equal to the capacity was accepted")
+	}
+}
+
+func TestForcedCheckedAgainstReserve(t *testing.T) {
+	// C and D weigh 45: they fit in 50, but not in 50 less a reserve of 10
+	opts, err := parseOptions([]string{"-force-include", "C,D", "-reserve", "10", "test@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = preprocess(opts, NewEmailBasedPackageGenerator().Generate("test@example.com"))
+	var infeasible *ErrForcedPackagesInfeasible
+	if !errors.As(err, &infeasible) {
+		t.Fatalf("preprocess error = %v, want *ErrForcedPackagesInfeasible", err)
+	}
+	if infeasible.Capacity != 40 {
+		t.Errorf("checked against capacity %d, want 40", infeasible.Capacity)
+	}
+}
+
+func TestForcedOptimizerInfeasible(t *testing.T) {
+	pkgs := NewEmailBasedPackageGenerator().Generate("test@example.com")
+	opt := &ForcedOptimizer{Inner: &PriorityBasedOptimizer{}, Forced: []string{"C", "D"}}
+	if got := opt.Optimize(pkgs, HeuristicContext{MaxLoad: 40}); got != nil {
+		t.Errorf("Optimize = %v, want nil", identifiers(got))
+	}
+	var infeasible *ErrForcedPackagesInfeasible
+	if !errors.As(opt.Err, &infeasible) {
+		t.Fatalf("Err = %v, want *ErrForcedPackagesInfeasible", opt.Err)
+	}
+
+	got := opt.Optimize(pkgs, HeuristicContext{MaxLoad: 50})
+	if opt.Err != nil {
+		t.Fatalf("Err = %v after a feasible run", opt.Err)
+	}
+	if ids := identifiers(got); len(ids) < 2 || ids[0] != "C" || ids[1] != "D" {
+		t.Errorf("Optimize = %v, want C and D first", ids)
+	}
+}
+
+func TestForcedOptimizerNothingElseFits(t *testing.T) {
+	// C and E leave no room for anything else at capacity 55, so greedy and
+	// SA select nothing from the rest; the forced packages must still load
+	pkgs := NewEmailBasedPackageGenerator().Generate("a@b.c")
+	for _, inner := range []LoadOptimizer{&GreedyOptimizer{}, &GreedyOptimizer{Lookahead: 2}, NewSimulatedAnnealingOptimizer(1)} {
+		opt := &ForcedOptimizer{Inner: inner, Forced: []string{"C", "E"}}
+		got := identifiers(opt.Optimize(pkgs, HeuristicContext{MaxLoad: 55, PriorityFactor: 1.0}))
+		if opt.Err != nil || !slices.Equal(got, []string{"C", "E"}) {
+			t.Errorf("%T: Optimize = %v, %v; want [C E], nil", inner, got, opt.Err)
+		}
+	}
+}
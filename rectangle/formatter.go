@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formatter renders an optimization result for stdout
+type Formatter interface {
+	Format(result OptimizationResult) string
+}
+
+// FormatterRegistry maps -format names to formatter constructors
+type FormatterRegistry map[string]func(*options) Formatter
+
+// Names lists the registered formats alphabetically
+func (r FormatterRegistry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatters is the registry behind -format
+var formatters = FormatterRegistry{
+	"text":       func(opts *options) Formatter { return TextFormatter{Compact: opts.compactOutput} },
+	"json":       func(opts *options) Formatter { return JSONFormatter{Compact: opts.compactOutput} },
+	"msgpack":    func(*options) Formatter { return MsgpackFormatter{} },
+	"csv":        func(*options) Formatter { return CSVFormatter{} },
+	"table":      func(*options) Formatter { return TableFormatter{} },
+	"markdown":   func(*options) Formatter { return MarkdownFormatter{} },
+	"proto":      func(opts *options) Formatter { return ProtoFormatter{Base64: opts.protoBase64} },
+	"proto-text": func(*options) Formatter { return ProtoTextFormatter{} },
+}
+
+// TextFormatter prints the comma-separated identifiers, or "No viable
+// packages"; compact output is the bare list even when empty
+type TextFormatter struct {
+	Compact bool
+}
+
+func (f TextFormatter) Format(result OptimizationResult) string {
+	if len(result.Selected) == 0 && !f.Compact {
+		return "No viable packages"
+	}
+	return strings.Join(result.Selected, ",")
+}
+
+// JSONFormatter prints the result as one line of JSON; compact drops the trailing newline
+type JSONFormatter struct {
+	Compact bool
+}
+
+func (f JSONFormatter) Format(result OptimizationResult) string {
+	b, err := json.Marshal(result)
+	if err != nil {
+		// OptimizationResult holds only strings, ints and floats
+		panic(fmt.Sprintf("internal error: marshal result: %v", err))
+	}
+	if f.Compact {
+		return string(b)
+	}
+	return string(b) + "\n"
+}
+
+// MsgpackFormatter prints the binary MessagePack encoding
+type MsgpackFormatter struct{}
+
+func (MsgpackFormatter) Format(result OptimizationResult) string {
+	return string(MarshalMsgpack(result))
+}
+
+// CSVFormatter prints a header and one row, with the selection as a quoted
+// list; optimal_count and package_set_hash are empty when not computed
+type CSVFormatter struct{}
+
+func (CSVFormatter) Format(result OptimizationResult) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write([]string{"email", "algorithm", "capacity", "selected", "total_mass", "total_value", "approximate", "optimal_count", "package_set_hash"})
+	optimalCount := ""
+	if result.OptimalCount != 0 {
+		optimalCount = fmt.Sprint(result.OptimalCount)
+	}
+	w.Write([]string{
+		result.Email, result.Algorithm, fmt.Sprint(result.Capacity),
+		strings.Join(result.Selected, ","), fmt.Sprint(result.TotalMass), fmt.Sprint(result.TotalValue),
+		fmt.Sprint(result.Approximate), optimalCount, result.PackageSetHash,
+	})
+	w.Flush()
+	return b.String()
+}
+
+// TableFormatter prints an aligned field/value table for terminals
+type TableFormatter struct{}
+
+func (TableFormatter) Format(result OptimizationResult) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, row := range resultRows(result) {
+		fmt.Fprintf(w, "%s\t%s\n", row[0], row[1])
+	}
+	w.Flush()
+	return b.String()
+}
+
+// MarkdownFormatter prints a two-column Markdown table
+type MarkdownFormatter struct{}
+
+func (MarkdownFormatter) Format(result OptimizationResult) string {
+	var b strings.Builder
+	b.WriteString("| Field | Value |\n|---|---|\n")
+	for _, row := range resultRows(result) {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], strings.ReplaceAll(row[1], "|", "\\|"))
+	}
+	return b.String()
+}
+
+// resultRows lists the result's fields as label/value pairs for tabular
+// formats; like the JSON, it leaves out the optional fields that are unset
+func resultRows(result OptimizationResult) [][2]string {
+	selected := strings.Join(result.Selected, ",")
+	if selected == "" {
+		selected = "none"
+	}
+	rows := [][2]string{
+		{"Email", result.Email},
+		{"Algorithm", result.Algorithm},
+		{"Capacity", num(result.Capacity)},
+		{"Selected", selected},
+		{"Total mass", num(result.TotalMass)},
+		{"Total value", num(result.TotalValue)},
+	}
+	if result.Approximate {
+		rows = append(rows, [2]string{"Approximate", "yes (greedy fallback after -timeout)"})
+	}
+	if result.OptimalCount != 0 {
+		rows = append(rows, [2]string{"Optimal solutions", num(int(result.OptimalCount))})
+	}
+	if result.PackageSetHash != "" {
+		rows = append(rows, [2]string{"Package set hash", result.PackageSetHash})
+	}
+	return rows
+}
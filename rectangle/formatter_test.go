@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestFormattersCarryResultFields checks that every structured format
+// includes approximate, optimal_count and package_set_hash, not only JSON
+func TestFormattersCarryResultFields(t *testing.T) {
+	result := OptimizationResult{
+		Email: "test@example.com", Algorithm: "dp", Capacity: 50,
+		Selected: []string{"A", "B"}, TotalMass: 30, TotalValue: 160,
+		Approximate: true, OptimalCount: 3, PackageSetHash: "d8ddc2d6",
+	}
+	text := map[string][]string{
+		"json":       {`"approximate":true`, `"optimal_count":3`, `"package_set_hash":"d8ddc2d6"`},
+		"csv":        {"approximate,optimal_count,package_set_hash\n", ",true,3,d8ddc2d6\n"},
+		"table":      {"Approximate", "Optimal solutions  3", "Package set hash   d8ddc2d6"},
+		"markdown":   {"| Approximate |", "| Optimal solutions | 3 |", "| Package set hash | d8ddc2d6 |"},
+		"proto-text": {"approximate: true\n", "optimal_count: 3\n", `package_set_hash: "d8ddc2d6"`},
+	}
+	for name, want := range text {
+		got := formatters[name](&options{}).Format(result)
+		for _, w := range want {
+			if !strings.Contains(got, w) {
+				t.Errorf("-format %s: %q missing from\n%s", name, w, got)
+			}
+		}
+	}
+
+	msgpack := MarshalMsgpack(result)
+	if msgpack[0] != 0x80|9 {
+		t.Errorf("msgpack map has %d fields, want 9", msgpack[0]&0x0f)
+	}
+	for _, w := range [][]byte{
+		append(appendMsgpackString(nil, "approximate"), 0xc3),
+		appendMsgpackInt(appendMsgpackString(nil, "optimal_count"), 3),
+		appendMsgpackString(appendMsgpackString(nil, "package_set_hash"), "d8ddc2d6"),
+	} {
+		if !bytes.Contains(msgpack, w) {
+			t.Errorf("msgpack lacks % x", w)
+		}
+	}
+
+	proto := MarshalProto(result)
+	for _, w := range [][]byte{
+		appendProtoInt(nil, 8, 1),
+		appendProtoInt(nil, 9, 3),
+		appendProtoString(nil, 10, "d8ddc2d6"),
+	} {
+		if !bytes.Contains(proto, w) {
+			t.Errorf("proto lacks % x", w)
+		}
+	}
+}
+
+func TestFormattersOmitUnsetResultFields(t *testing.T) {
+	result := OptimizationResult{Email: "test@example.com", Algorithm: "dp", Capacity: 50}
+	for _, name := range []string{"json", "table", "markdown", "proto-text"} {
+		got := strings.ToLower(formatters[name](&options{}).Format(result))
+		for _, field := range []string{"approximate", "optimal", "hash"} {
+			if strings.Contains(got, field) {
+				t.Errorf("-format %s shows unset %s:\n%s", name, field, got)
+			}
+		}
+	}
+	if got := MarshalMsgpack(result)[0]; got != 0x80|6 {
+		t.Errorf("msgpack map has %d fields, want 6", got&0x0f)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitCatalogSpec is a -catalog-from-git repo:path:ref reference
+type gitCatalogSpec struct {
+	Repo, Path, Ref string
+}
+
+// parseGitCatalogSpec splits repo:path:ref from the right, so the repository
+// may itself contain colons (https://host/x.git, git@host:x.git)
+func parseGitCatalogSpec(s string) (gitCatalogSpec, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 3 {
+		return gitCatalogSpec{}, fmt.Errorf("invalid git catalog %q (want repo:path:ref)", s)
+	}
+	n := len(parts)
+	spec := gitCatalogSpec{Repo: strings.Join(parts[:n-2], ":"), Path: parts[n-2], Ref: parts[n-1]}
+	if spec.Repo == "" || spec.Path == "" || spec.Ref == "" {
+		return gitCatalogSpec{}, fmt.Errorf("invalid git catalog %q (want repo:path:ref)", s)
+	}
+	// git would take these as options
+	if strings.HasPrefix(spec.Repo, "-") || strings.HasPrefix(spec.Ref, "-") {
+		return gitCatalogSpec{}, fmt.Errorf("invalid git catalog %q: repository and ref may not start with -", s)
+	}
+	return spec, nil
+}
+
+// isCommitHash reports whether ref is a full SHA-1 or SHA-256 object name,
+// which unlike a branch or tag always names the same commit
+func isCommitHash(ref string) bool {
+	if len(ref) != 40 && len(ref) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(ref)
+	return err == nil
+}
+
+// git runs a git command and returns its stdout, with stderr in the error
+func git(args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// gitCatalogRepo returns a local repository for spec.Repo: the directory
+// itself when it exists, otherwise a bare clone cached under the user cache
+// directory and reused by later runs. cached reports a clone from an
+// earlier run, which may be behind the remote.
+func gitCatalogRepo(repo string) (dir string, cached bool, err error) {
+	if info, err := os.Stat(repo); err == nil && info.IsDir() {
+		return repo, false, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", false, err
+	}
+	sum := sha256.Sum256([]byte(repo))
+	dir = filepath.Join(cache, "optimizer", "git", hex.EncodeToString(sum[:8]))
+	if _, err := os.Stat(dir); err == nil {
+		return dir, true, nil
+	}
+	if _, err := git("clone", "--quiet", "--bare", "--", repo, dir); err != nil {
+		return "", false, err
+	}
+	return dir, false, nil
+}
+
+// fetchAll updates every ref of a cached clone from its origin
+func fetchAll(dir string) error {
+	_, err := git("-C", dir, "fetch", "--quiet", "origin", "+refs/*:refs/*")
+	return err
+}
+
+// loadGitCatalog reads the catalog at spec.Path as of spec.Ref without
+// touching any working tree. A cached clone is fetched before reading a
+// branch or tag, which may have moved, and only when missing the object
+// for a full commit hash.
+func loadGitCatalog(s string, skipBad, strictJSON bool) ([]PackageMetadata, error) {
+	spec, err := parseGitCatalogSpec(s)
+	if err != nil {
+		return nil, err
+	}
+	dir, cached, err := gitCatalogRepo(spec.Repo)
+	if err != nil {
+		return nil, err
+	}
+	pinned := isCommitHash(spec.Ref)
+	if cached && !pinned {
+		if err := fetchAll(dir); err != nil {
+			return nil, err
+		}
+	}
+	object := spec.Ref + ":" + spec.Path
+	data, err := git("-C", dir, "show", "--end-of-options", object)
+	if err != nil && cached && pinned {
+		if ferr := fetchAll(dir); ferr != nil {
+			return nil, ferr
+		}
+		data, err = git("-C", dir, "show", "--end-of-options", object)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return readCatalog(bytes.NewReader(data), spec.Path, skipBad, strictJSON)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitCatalogSpecRejectsOptions(t *testing.T) {
+	for _, s := range []string{"--upload-pack=touch /tmp/x:c.json:main", "repo:c.json:--output=/tmp/f", "-c:c.json:main"} {
+		if _, err := parseGitCatalogSpec(s); err == nil {
+			t.Errorf("parseGitCatalogSpec(%q) accepted an option", s)
+		}
+	}
+	spec, err := parseGitCatalogSpec("https://host/x.git:data/c.json:main")
+	if err != nil || spec.Repo != "https://host/x.git" || spec.Path != "data/c.json" || spec.Ref != "main" {
+		t.Errorf("parseGitCatalogSpec = %+v, %v", spec, err)
+	}
+}
+
+func TestLoadGitCatalogFollowsMovingRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	src := t.TempDir()
+	commit := func(catalog string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(src, "c.csv"), []byte(catalog), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{{"add", "c.csv"}, {"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "--quiet", "-m", "catalog"}} {
+			if _, err := git(append([]string{"-C", src}, args...)...); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err := git("init", "--quiet", "--initial-branch=main", src); err != nil {
+		t.Fatal(err)
+	}
+	commit("A,10,60\n")
+	first, err := git("-C", src, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a file:// URL is cloned into the cache rather than read in place
+	url := "file://" + src
+	load := func(ref string) string {
+		t.Helper()
+		pkgs, err := loadGitCatalog(url+":c.csv:"+ref, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(identifiers(pkgs), ",")
+	}
+	if got := load("main"); got != "A" {
+		t.Fatalf("first load = %s, want A", got)
+	}
+	commit("A,10,60\nB,20,100\n")
+	if got := load("main"); got != "A,B" {
+		t.Errorf("load after main moved = %s, want A,B", got)
+	}
+	if got := load(strings.TrimSpace(string(first))); got != "A" {
+		t.Errorf("load of the first commit = %s, want A", got)
+	}
+}
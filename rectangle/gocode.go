@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"text/template"
+)
+
+// goProgram is the standalone solver emitted by -emit-go-code
+var goProgram = template.Must(template.New("program").Parse(`// Code generated by -emit-go-code for {{printf "%q" .Email}}. DO NOT EDIT.
+//
+// Solves one truck loading instance with the 0/1 knapsack DP; run with go run.
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// capacity is the truck's MaxLoad
+const capacity = {{.Capacity}}
+
+// packages are the instance's identifiers, masses and values
+var packages = []struct {
+	id    string
+	mass  int
+	value int
+}{
+{{- range .Packages}}
+	{ {{- printf "%q" .Identifier}}, {{.MassConstraint}}, {{.Valuation -}} },
+{{- end}}
+}
+
+func main() {
+	n := len(packages)
+	dp := make([][]int, n+1)
+	for i := range dp {
+		dp[i] = make([]int, capacity+1)
+	}
+	for i := 1; i <= n; i++ {
+		p := packages[i-1]
+		for w := 0; w <= capacity; w++ {
+			dp[i][w] = dp[i-1][w]
+			if p.mass <= w && dp[i-1][w-p.mass]+p.value > dp[i][w] {
+				dp[i][w] = dp[i-1][w-p.mass] + p.value
+			}
+		}
+	}
+
+	var selected []string
+	for i, w := n, capacity; i > 0; i-- {
+		p := packages[i-1]
+		if p.mass <= w && dp[i][w] == dp[i-1][w-p.mass]+p.value {
+			selected = append(selected, p.id)
+			w -= p.mass
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Print("No viable packages")
+		return
+	}
+	sort.Strings(selected)
+	fmt.Print(strings.Join(selected, ","))
+}
+`))
+
+// writeGoProgram renders the instance as a gofmt'd standalone Go program
+func writeGoProgram(path, email string, pkgs []PackageMetadata, ctx HeuristicContext) error {
+	var buf bytes.Buffer
+	err := goProgram.Execute(&buf, struct {
+		Email    string
+		Capacity int
+		Packages []PackageMetadata
+	}{email, ctx.MaxLoad, pkgs})
+	if err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated program: %w", err)
+	}
+	return os.WriteFile(path, src, 0o644)
+}
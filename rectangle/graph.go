@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// WriteDOT renders the dependency (directed) and conflict (undirected) graphs as Graphviz DOT
+func WriteDOT(w io.Writer, pkgs []PackageMetadata) error {
+	if _, err := fmt.Fprintln(w, "digraph packages {"); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "\tnode [shape=box];")
+	for _, p := range pkgs {
+		fmt.Fprintf(w, "\t%q [label=\"%s\\nw=%d v=%d\"];\n", p.Identifier, p.Identifier, p.MassConstraint, p.Valuation)
+	}
+
+	// Conflicts are symmetric, so each unordered pair is drawn once
+	seen := make(map[[2]string]bool)
+	for _, p := range pkgs {
+		for _, dep := range p.Dependencies {
+			fmt.Fprintf(w, "\t%q -> %q [color=blue];\n", p.Identifier, dep)
+		}
+		for _, other := range p.ExcludedBy {
+			key := [2]string{p.Identifier, other}
+			if other < p.Identifier {
+				key = [2]string{other, p.Identifier}
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			fmt.Fprintf(w, "\t%q -> %q [color=red, dir=none, style=dashed];\n", key[0], key[1])
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// exportGraph writes the DOT graph for pkgs to path
+func exportGraph(path string, pkgs []PackageMetadata) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteDOT(f, pkgs); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Group awards GroupBonus when every one of its Members is selected
+type Group struct {
+	GroupID    string
+	GroupBonus int
+	Members    []string
+}
+
+// groupFlag collects repeated -group-bonus=ID:bonus:A,B groups
+type groupFlag []Group
+
+func (f *groupFlag) String() string {
+	specs := make([]string, len(*f))
+	for i, g := range *f {
+		specs[i] = fmt.Sprintf("%s:%d:%s", g.GroupID, g.GroupBonus, strings.Join(g.Members, ","))
+	}
+	return strings.Join(specs, " ")
+}
+
+func (f *groupFlag) Set(s string) error {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("group %q: want ID:bonus:A,B", s)
+	}
+	bonus, err := strconv.Atoi(parts[1])
+	if err != nil || bonus < 0 {
+		return fmt.Errorf("group %q: invalid bonus %q", s, parts[1])
+	}
+	g := Group{GroupID: parts[0], GroupBonus: bonus, Members: parseForceInclude(parts[2])}
+	if len(g.Members) == 0 {
+		return fmt.Errorf("group %q has no members", s)
+	}
+	for _, other := range *f {
+		for _, a := range other.Members {
+			for _, b := range g.Members {
+				if a == b {
+					return fmt.Errorf("package %s is in groups %s and %s", a, other.GroupID, g.GroupID)
+				}
+			}
+		}
+	}
+	*f = append(*f, g)
+	return nil
+}
+
+// GroupAwareBonusOptimizer maximizes package value plus the bonus of every
+// group whose members are all selected. The bonus couples the members, so
+// the objective is no longer a sum over items. The DP keeps the usual rows
+// for the members (any subset, no bonus) and adds one row per group for the
+// "all members" state: bundle[w] = max(members[w], before[w-M] + V + bonus)
+// with M and V the group's total mass and value. O((n+g)·W) time and space.
+type GroupAwareBonusOptimizer struct {
+	Groups []Group
+}
+
+// groupStage is one DP row: a single package, or a group's bundle row that
+// can jump back to the row before the group's first member
+type groupStage struct {
+	pkg    PackageMetadata
+	group  *Group
+	before int               // bundle: row index preceding the members
+	all    []PackageMetadata // bundle: the members present in the catalog
+}
+
+// Optimize returns the selection maximizing value plus earned group bonuses
+func (o *GroupAwareBonusOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	stages := o.plan(pkgs)
+	W := ctx.MaxLoad
+	dp := make([][]int, len(stages)+1)
+	dp[0] = make([]int, W+1)
+	for s, st := range stages {
+		prev, row := dp[s], make([]int, W+1)
+		for w := 0; w <= W; w++ {
+			row[w] = prev[w]
+			if st.group == nil {
+				if m := st.pkg.MassConstraint; m <= w && dp[s][w-m]+st.pkg.Valuation > row[w] {
+					row[w] = dp[s][w-m] + st.pkg.Valuation
+				}
+			} else if m := totalMass(st.all); m <= w {
+				if v := dp[st.before][w-m] + totalValue(st.all) + st.group.GroupBonus; v > row[w] {
+					row[w] = v
+				}
+			}
+		}
+		dp[s+1] = row
+	}
+
+	res := []PackageMetadata{}
+	for s, w := len(stages), W; s > 0; {
+		st := stages[s-1]
+		switch {
+		case st.group != nil && dp[s][w] != dp[s-1][w]:
+			// the bundle row improved on any member subset: take the whole group
+			res = append(res, st.all...)
+			w -= totalMass(st.all)
+			s = st.before
+		case st.group == nil && st.pkg.MassConstraint <= w && dp[s][w] != dp[s-1][w]:
+			res = append(res, st.pkg)
+			w -= st.pkg.MassConstraint
+			s--
+		default:
+			s--
+		}
+	}
+	return res
+}
+
+// plan orders the catalog into DP stages, each group's members followed by
+// its bundle row
+func (o *GroupAwareBonusOptimizer) plan(pkgs []PackageMetadata) []groupStage {
+	groupOf := make(map[string]int)
+	for g, grp := range o.Groups {
+		for _, id := range grp.Members {
+			groupOf[id] = g
+		}
+	}
+	members := make([][]PackageMetadata, len(o.Groups))
+	var stages []groupStage
+	for _, p := range pkgs {
+		if g, ok := groupOf[p.Identifier]; ok {
+			members[g] = append(members[g], p)
+		} else {
+			stages = append(stages, groupStage{pkg: p})
+		}
+	}
+	for g := range o.Groups {
+		// a group missing some of its members can never earn its bonus
+		if len(members[g]) != len(o.Groups[g].Members) {
+			for _, p := range members[g] {
+				stages = append(stages, groupStage{pkg: p})
+			}
+			continue
+		}
+		before := len(stages)
+		for _, p := range members[g] {
+			stages = append(stages, groupStage{pkg: p})
+		}
+		stages = append(stages, groupStage{group: &o.Groups[g], before: before, all: members[g]})
+	}
+	return stages
+}
+
+// EarnedBonuses returns the groups whose members are all in selected and their total bonus
+func (o *GroupAwareBonusOptimizer) EarnedBonuses(selected []PackageMetadata) ([]string, int) {
+	chosen := make(map[string]bool, len(selected))
+	for _, p := range selected {
+		chosen[p.Identifier] = true
+	}
+	var ids []string
+	total := 0
+	for _, g := range o.Groups {
+		all := true
+		for _, id := range g.Members {
+			all = all && chosen[id]
+		}
+		if all {
+			ids = append(ids, g.GroupID)
+			total += g.GroupBonus
+		}
+	}
+	return ids, total
+}
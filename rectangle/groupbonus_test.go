@@ -0,0 +1,76 @@
+package main
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestGroupBonusTakesWholeGroup(t *testing.T) {
+	pkgs := []PackageMetadata{
+		{Identifier: "A", MassConstraint: 5, Valuation: 30},
+		{Identifier: "B", MassConstraint: 5, Valuation: 30},
+		{Identifier: "C", MassConstraint: 10, Valuation: 70},
+	}
+	ctx := HeuristicContext{MaxLoad: 10}
+	tests := []struct {
+		bonus int
+		want  []string
+	}{
+		{bonus: 0, want: []string{"C"}},       // A+B 60 < 70
+		{bonus: 10, want: []string{"C"}},      // 70, a tie keeps the single package
+		{bonus: 20, want: []string{"A", "B"}}, // A+B 80 > 70
+	}
+	for _, tt := range tests {
+		opt := &GroupAwareBonusOptimizer{Groups: []Group{{GroupID: "G", GroupBonus: tt.bonus, Members: []string{"A", "B"}}}}
+		selected := opt.Optimize(pkgs, ctx)
+		ids := identifiers(selected)
+		sort.Strings(ids)
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("bonus %d: selected %v, want %v", tt.bonus, ids, tt.want)
+		}
+		wantBonus := 0
+		if len(tt.want) == 2 {
+			wantBonus = tt.bonus
+		}
+		if earned, total := opt.EarnedBonuses(selected); total != wantBonus {
+			t.Errorf("bonus %d: earned %v worth %d, want %d", tt.bonus, earned, total, wantBonus)
+		}
+	}
+}
+
+func TestGroupBonusMatchesBruteForce(t *testing.T) {
+	groups := []Group{
+		{GroupID: "G1", GroupBonus: 25, Members: []string{"S0", "S1", "S2"}},
+		{GroupID: "G2", GroupBonus: 40, Members: []string{"S4", "S5"}},
+	}
+	opt := &GroupAwareBonusOptimizer{Groups: groups}
+	for seed := uint64(1); seed <= 20; seed++ {
+		pkgs := appendSynthetic(nil, 8, 15, seed)
+		for _, W := range []int{10, 25, 40} {
+			selected := opt.Optimize(pkgs, HeuristicContext{MaxLoad: W})
+			if m := totalMass(selected); m > W {
+				t.Fatalf("seed %d, capacity %d: mass %d over capacity", seed, W, m)
+			}
+			_, bonus := opt.EarnedBonuses(selected)
+			got := totalValue(selected) + bonus
+
+			best := 0
+			for mask := 0; mask < 1<<len(pkgs); mask++ {
+				var sub []PackageMetadata
+				for i, p := range pkgs {
+					if mask&(1<<i) != 0 {
+						sub = append(sub, p)
+					}
+				}
+				if totalMass(sub) <= W {
+					_, b := opt.EarnedBonuses(sub)
+					best = max(best, totalValue(sub)+b)
+				}
+			}
+			if got != best {
+				t.Errorf("seed %d, capacity %d: value with bonuses %d, want %d", seed, W, got, best)
+			}
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import "fmt"
+
+// healthCheckPackages is a small instance whose optimum (B and C, value 220)
+// differs from the greedy density choice (A and B, value 160), so a broken
+// DP that degrades to greedy is caught too
+var healthCheckPackages = []PackageMetadata{
+	{Identifier: "A", MassConstraint: 10, Valuation: 60},
+	{Identifier: "B", MassConstraint: 20, Valuation: 100},
+	{Identifier: "C", MassConstraint: 30, Valuation: 120},
+}
+
+// OptimizerHealthCheck runs optimizer on a known-optimal three-package
+// instance and returns an error describing the difference when its
+// selection is not the expected one
+func OptimizerHealthCheck(optimizer LoadOptimizer) error {
+	ctx := HeuristicContext{MaxLoad: 50, PriorityFactor: 1.0}
+	const want, wantValue = "B,C", 220
+
+	got := optimizer.Optimize(healthCheckPackages, ctx)
+	if selected := formatSelection(got); selected != want || totalValue(got) != wantValue {
+		return fmt.Errorf("health check: %T selected %s (mass %d, value %d) at capacity %d, want %s (value %d)",
+			optimizer, selected, totalMass(got), totalValue(got), ctx.MaxLoad, want, wantValue)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// histogramBuckets is the number of value ranges in -value-histogram
+const histogramBuckets = 5
+
+// printValueHistogram writes an ASCII histogram of package valuations
+func printValueHistogram(w io.Writer, pkgs []PackageMetadata) {
+	if len(pkgs) == 0 {
+		fmt.Fprintln(w, "value histogram: no packages selected")
+		return
+	}
+
+	lo, hi := pkgs[0].Valuation, pkgs[0].Valuation
+	for _, p := range pkgs {
+		lo = min(lo, p.Valuation)
+		hi = max(hi, p.Valuation)
+	}
+	width := (hi-lo)/histogramBuckets + 1
+
+	counts := make([]int, histogramBuckets)
+	for _, p := range pkgs {
+		counts[(p.Valuation-lo)/width]++
+	}
+
+	fmt.Fprintln(w, "value histogram:")
+	for b, c := range counts {
+		start := lo + b*width
+		if start > hi {
+			break
+		}
+		fmt.Fprintf(w, "  %4d-%-4d | %s %d\n", start, start+width-1, strings.Repeat("#", c), c)
+	}
+}
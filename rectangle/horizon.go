@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DayPlan is the selection loaded on one day of a horizon plan
+type DayPlan struct {
+	Day        int
+	Selected   []PackageMetadata
+	Cumulative int
+}
+
+// parseArrivals parses "A:1,B:2" into per-package arrival days
+func parseArrivals(spec string) (map[string]int, error) {
+	arrivals := make(map[string]int)
+	if spec == "" {
+		return arrivals, nil
+	}
+	for _, tok := range strings.Split(spec, ",") {
+		id, day, ok := strings.Cut(tok, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid arrival %q: want identifier:day", tok)
+		}
+		d, err := strconv.Atoi(day)
+		if err != nil || d < 1 {
+			return nil, fmt.Errorf("invalid arrival day in %q", tok)
+		}
+		arrivals[id] = d
+	}
+	return arrivals, nil
+}
+
+// PlanHorizon runs one knapsack per day over the packages that have arrived
+// and not yet shipped; unloaded packages carry over to the next day.
+// Packages without an arrival entry are available from day 1.
+func PlanHorizon(pkgs []PackageMetadata, arrivals map[string]int, days int, optimizer LoadOptimizer, ctx HeuristicContext) []DayPlan {
+	shipped := make(map[string]bool)
+	plans := make([]DayPlan, 0, days)
+	cumulative := 0
+	for day := 1; day <= days; day++ {
+		var available []PackageMetadata
+		for _, p := range pkgs {
+			arrival, ok := arrivals[p.Identifier]
+			if !ok {
+				arrival = 1
+			}
+			if arrival <= day && !shipped[p.Identifier] {
+				available = append(available, p)
+			}
+		}
+
+		selected := optimizer.Optimize(available, ctx)
+		sort.Slice(selected, func(i, j int) bool {
+			return selected[i].Identifier < selected[j].Identifier
+		})
+		for _, p := range selected {
+			shipped[p.Identifier] = true
+		}
+		cumulative += totalValue(selected)
+		plans = append(plans, DayPlan{Day: day, Selected: selected, Cumulative: cumulative})
+	}
+	return plans
+}
+
+// printHorizon writes one line per day with its selection and cumulative value
+func printHorizon(w io.Writer, plans []DayPlan) {
+	for _, p := range plans {
+		ids := make([]string, len(p.Selected))
+		for i, pkg := range p.Selected {
+			ids[i] = pkg.Identifier
+		}
+		loaded := strings.Join(ids, ",")
+		if loaded == "" {
+			loaded = "-"
+		}
+		fmt.Fprintf(w, "day %d: %s (value %s, cumulative %s)\n", p.Day, loaded, num(totalValue(p.Selected)), num(p.Cumulative))
+	}
+}
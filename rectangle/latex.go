@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// latexEscaper escapes characters with special meaning in LaTeX text
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`&`, `\&`, `%`, `\%`, `$`, `\$`, `#`, `\#`, `_`, `\_`,
+	`{`, `\{`, `}`, `\}`, `~`, `\textasciitilde{}`, `^`, `\textasciicircum{}`,
+)
+
+// WriteLatexTable writes the catalog as a LaTeX tabular, shading selected
+// rows with \rowcolor (needs \usepackage[table]{xcolor})
+func WriteLatexTable(pkgs []PackageMetadata, selected []PackageMetadata, w io.Writer) error {
+	chosen := make(map[string]bool, len(selected))
+	for _, p := range selected {
+		chosen[p.Identifier] = true
+	}
+	var b strings.Builder
+	b.WriteString("\\begin{tabular}{lrr}\n\\hline\nPackage & Mass & Value \\\\\n\\hline\n")
+	for _, p := range pkgs {
+		if chosen[p.Identifier] {
+			b.WriteString("\\rowcolor{green!20}\n")
+		}
+		fmt.Fprintf(&b, "%s & %d & %d \\\\\n", latexEscaper.Replace(p.Identifier), p.MassConstraint, p.Valuation)
+	}
+	fmt.Fprintf(&b, "\\hline\nTotal selected & %d & %d \\\\\n\\hline\n\\end{tabular}\n", totalMass(selected), totalValue(selected))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// emitLatex writes the LaTeX table for the result to path
+func emitLatex(path string, pkgs, selected []PackageMetadata) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteLatexTable(pkgs, selected, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,63 @@
+package main
+
+// LazyDP solves the 0/1 knapsack top-down with memoized recursion, starting
+// from the backtracking cell dp[n][W] and computing only the cells that cell
+// depends on. When few capacities are reachable (large, coarse masses) this
+// touches far fewer than the n*(W+1) cells of the bottom-up fill.
+type LazyDP struct {
+	// CellsComputed is the number of distinct cells evaluated by the last Optimize
+	CellsComputed int
+	// steps is the number of rows the last Optimize backtracked through
+	steps int
+
+	pkgs []PackageMetadata
+	cols int
+	memo map[int]int
+}
+
+// Optimize backtracks from dp[n][W], computing cells on demand
+func (o *LazyDP) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	o.pkgs = pkgs
+	o.cols = ctx.MaxLoad + 1
+	o.memo = make(map[int]int)
+	o.steps = 0
+
+	res := []PackageMetadata{}
+	w := ctx.MaxLoad
+	for i := len(pkgs); i > 0; i-- {
+		o.steps++
+		wt := pkgs[i-1].MassConstraint
+		val := pkgs[i-1].Valuation
+		if wt <= w && o.cell(i, w) == o.cell(i-1, w-wt)+val {
+			res = append(res, pkgs[i-1])
+			w -= wt
+		}
+	}
+	o.CellsComputed = len(o.memo)
+	return res
+}
+
+// Work returns the cells computed and rows backtracked by the last Optimize
+func (o *LazyDP) Work() DPCounters {
+	return DPCounters{CellsComputed: o.CellsComputed, BacktrackSteps: o.steps}
+}
+
+// cell returns dp[i][w], computing and memoizing it on first use
+func (o *LazyDP) cell(i, w int) int {
+	if i == 0 {
+		return 0
+	}
+	key := i*o.cols + w
+	if v, ok := o.memo[key]; ok {
+		return v
+	}
+	best := o.cell(i-1, w) // skip
+	wt := o.pkgs[i-1].MassConstraint
+	if wt <= w {
+		if candidate := o.cell(i-1, w-wt) + o.pkgs[i-1].Valuation; candidate > best {
+			best = candidate
+		}
+	}
+	o.memo[key] = best
+	return best
+}
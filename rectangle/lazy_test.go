@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+// coarseCatalog has n packages with masses in multiples of 100, so only a
+// few capacities are reachable and LazyDP touches few cells
+func coarseCatalog(n int, seed uint64) []PackageMetadata {
+	pkgs := appendSynthetic(nil, n, 20, seed)
+	for i := range pkgs {
+		pkgs[i].MassConstraint *= 100
+	}
+	return pkgs
+}
+
+func TestLazyDPMatchesFullDP(t *testing.T) {
+	for seed := uint64(1); seed <= 10; seed++ {
+		for _, pkgs := range [][]PackageMetadata{appendSynthetic(nil, 15, 40, seed), coarseCatalog(15, seed)} {
+			ctx := HeuristicContext{MaxLoad: 2000}
+			full := totalValue((&PriorityBasedOptimizer{}).Optimize(pkgs, ctx))
+			lazy := &LazyDP{}
+			if got := totalValue(lazy.Optimize(pkgs, ctx)); got != full {
+				t.Errorf("seed %d: lazy value %d, full DP %d", seed, got, full)
+			}
+			if lazy.CellsComputed > len(pkgs)*(ctx.MaxLoad+1) {
+				t.Errorf("seed %d: lazy computed %d cells, more than the full table", seed, lazy.CellsComputed)
+			}
+		}
+	}
+}
+
+// BenchmarkLazyVsFullDP solves 40 packages at W=20000 with the lazy and
+// full DP, on coarse masses (where most cells are never needed) and on
+// fine ones; both have optimal selections of many packages
+func BenchmarkLazyVsFullDP(b *testing.B) {
+	ctx := HeuristicContext{MaxLoad: 20000}
+	catalogs := []struct {
+		name string
+		pkgs []PackageMetadata
+	}{
+		{"coarse", coarseCatalog(40, 1)},
+		{"fine", appendSynthetic(nil, 40, 1000, 1)},
+	}
+	for _, c := range catalogs {
+		b.Run(c.name+"/full", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				(&PriorityBasedOptimizer{}).Optimize(c.pkgs, ctx)
+			}
+		})
+		b.Run(c.name+"/lazy", func(b *testing.B) {
+			lazy := &LazyDP{}
+			for i := 0; i < b.N; i++ {
+				lazy.Optimize(c.pkgs, ctx)
+			}
+			b.ReportMetric(float64(lazy.CellsComputed), "cells")
+		})
+	}
+}
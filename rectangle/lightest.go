@@ -0,0 +1,47 @@
+package main
+
+// valueMass is a DP cell ordered by value, then by lower mass
+type valueMass struct {
+	value, mass int
+}
+
+// better reports whether a beats b: more value, or equal value and less mass
+func (a valueMass) better(b valueMass) bool {
+	return a.value > b.value || a.value == b.value && a.mass < b.mass
+}
+
+// LightestOptimalOptimizer is the DP with cells holding (value, mass) pairs
+// compared lexicographically, so among selections of equal value it returns
+// one of least total mass
+type LightestOptimalOptimizer struct{}
+
+// Optimize returns an optimal selection of minimal mass among the optima
+func (o *LightestOptimalOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	n, W := len(pkgs), ctx.MaxLoad
+	dp := make([][]valueMass, n+1)
+	for i := range dp {
+		dp[i] = make([]valueMass, W+1)
+	}
+	for i := 1; i <= n; i++ {
+		wt, val := pkgs[i-1].MassConstraint, pkgs[i-1].Valuation
+		for w := 0; w <= W; w++ {
+			dp[i][w] = dp[i-1][w]
+			if wt <= w {
+				take := valueMass{dp[i-1][w-wt].value + val, dp[i-1][w-wt].mass + wt}
+				if take.better(dp[i][w]) {
+					dp[i][w] = take
+				}
+			}
+		}
+	}
+
+	res := []PackageMetadata{}
+	for i, w := n, W; i > 0; i-- {
+		wt, val := pkgs[i-1].MassConstraint, pkgs[i-1].Valuation
+		if wt <= w && dp[i][w] == (valueMass{dp[i-1][w-wt].value + val, dp[i-1][w-wt].mass + wt}) {
+			res = append(res, pkgs[i-1])
+			w -= wt
+		}
+	}
+	return res
+}
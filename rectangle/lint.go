@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Limits enforced by -strict-types
+const (
+	strictMaxMass  = 1000000
+	strictMaxValue = 10000000
+)
+
+// strictIdentifier is the identifier form -strict-types accepts
+var strictIdentifier = regexp.MustCompile(`^[A-Z][A-Z0-9]*$`)
+
+// LintWarning describes a suspicious catalog entry
+type LintWarning struct {
+	Identifier string
+	Message    string
+}
+
+func (w LintWarning) String() string {
+	return fmt.Sprintf("%s: %s", w.Identifier, w.Message)
+}
+
+// LintCatalog checks a catalog for common mistakes: duplicate identifiers,
+// dependencies on missing packages, isolated conflict pairs, packages heavier
+// than the default capacity and zero-value packages
+func LintCatalog(pkgs []PackageMetadata) []LintWarning {
+	var warnings []LintWarning
+
+	byID := make(map[string]PackageMetadata, len(pkgs))
+	for _, p := range pkgs {
+		if _, dup := byID[p.Identifier]; dup {
+			warnings = append(warnings, LintWarning{p.Identifier, "duplicate identifier"})
+			continue
+		}
+		byID[p.Identifier] = p
+	}
+
+	for _, p := range pkgs {
+		for _, dep := range p.Dependencies {
+			if _, ok := byID[dep]; !ok {
+				warnings = append(warnings, LintWarning{p.Identifier, fmt.Sprintf("depends on %s, which is not in the catalog", dep)})
+			}
+		}
+		if p.MassConstraint > defaultMaxLoad {
+			warnings = append(warnings, LintWarning{p.Identifier, fmt.Sprintf("mass %d exceeds default capacity %d", p.MassConstraint, defaultMaxLoad)})
+		}
+		if p.Valuation == 0 {
+			warnings = append(warnings, LintWarning{p.Identifier, "zero valuation"})
+		}
+	}
+
+	// A pair that only conflicts with each other leaves no alternative: at most one can ever load
+	conflicts := make(map[string]map[string]bool)
+	for _, p := range pkgs {
+		for _, other := range p.ExcludedBy {
+			if conflicts[p.Identifier] == nil {
+				conflicts[p.Identifier] = make(map[string]bool)
+			}
+			if conflicts[other] == nil {
+				conflicts[other] = make(map[string]bool)
+			}
+			conflicts[p.Identifier][other] = true
+			conflicts[other][p.Identifier] = true
+		}
+	}
+	ids := make([]string, 0, len(conflicts))
+	for id := range conflicts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, a := range ids {
+		for b := range conflicts[a] {
+			if a < b && len(conflicts[a]) == 1 && len(conflicts[b]) == 1 {
+				warnings = append(warnings, LintWarning{a, fmt.Sprintf("isolated conflict pair with %s; at most one of them can be loaded", b)})
+			}
+		}
+	}
+
+	return warnings
+}
+
+// CheckUniqueIdentifiers returns one error per repeated identifier, naming
+// the positions of the first package and of every duplicate
+func CheckUniqueIdentifiers(pkgs []PackageMetadata) error {
+	first := make(map[string]int, len(pkgs))
+	var errs []error
+	for i, p := range pkgs {
+		if j, dup := first[p.Identifier]; dup {
+			errs = append(errs, fmt.Errorf("duplicate identifier %q: package %d repeats package %d", p.Identifier, i+1, j+1))
+			continue
+		}
+		first[p.Identifier] = i
+	}
+	return errors.Join(errs...)
+}
+
+// CheckStrictTypes returns one error per field violating the -strict-types
+// rules: identifiers of the form [A-Z][A-Z0-9]*, masses in [1, 1000000) and
+// values in [1, 10000000)
+func CheckStrictTypes(pkgs []PackageMetadata) error {
+	var errs []error
+	for i, p := range pkgs {
+		if !strictIdentifier.MatchString(p.Identifier) {
+			errs = append(errs, fmt.Errorf("package %d: identifier %q does not match %s", i+1, p.Identifier, strictIdentifier))
+		}
+		if p.MassConstraint < 1 || p.MassConstraint >= strictMaxMass {
+			errs = append(errs, fmt.Errorf("package %s: mass %d outside [1, %d)", p.Identifier, p.MassConstraint, strictMaxMass))
+		}
+		if p.Valuation < 1 || p.Valuation >= strictMaxValue {
+			errs = append(errs, fmt.Errorf("package %s: value %d outside [1, %d)", p.Identifier, p.Valuation, strictMaxValue))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CheckDegenerate returns an error if a dynamic package has the same mass and
+// value as a base package, which makes the catalog degenerate for analysis
+func CheckDegenerate(base, pkgs []PackageMetadata) error {
+	for _, p := range pkgs[len(base):] {
+		for _, b := range base {
+			if p.MassConstraint == b.MassConstraint && p.Valuation == b.Valuation {
+				return fmt.Errorf("degenerate catalog: %s duplicates %s (mass=%d value=%d)", p.Identifier, b.Identifier, p.MassConstraint, p.Valuation)
+			}
+		}
+	}
+	return nil
+}
+
+// dynamicRanges are the attribute ranges Generate promises for X and Y
+var dynamicRanges = []struct {
+	id                 string
+	minMass, maxMass   int
+	minValue, maxValue int
+}{
+	{"X", 5, 19, 40, 89},
+	{"Y", 8, 17, 50, 89},
+}
+
+// CheckSeedRange returns an error describing every dynamic package attribute
+// outside its documented range, which would point at a seeding bug
+func CheckSeedRange(base, pkgs []PackageMetadata) error {
+	dynamic := pkgs[len(base):]
+	if len(dynamic) < len(dynamicRanges) {
+		return fmt.Errorf("seed range: %d dynamic packages generated, want %d", len(dynamic), len(dynamicRanges))
+	}
+	var errs []error
+	for i, r := range dynamicRanges {
+		p := dynamic[i]
+		if p.Identifier != r.id {
+			errs = append(errs, fmt.Errorf("seed range: dynamic package %d is %s, want %s", i, p.Identifier, r.id))
+			continue
+		}
+		if p.MassConstraint < r.minMass || p.MassConstraint > r.maxMass {
+			errs = append(errs, fmt.Errorf("seed range: %s mass %d outside [%d, %d]", p.Identifier, p.MassConstraint, r.minMass, r.maxMass))
+		}
+		if p.Valuation < r.minValue || p.Valuation > r.maxValue {
+			errs = append(errs, fmt.Errorf("seed range: %s value %d outside [%d, %d]", p.Identifier, p.Valuation, r.minValue, r.maxValue))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mustBeInCatalogOrder panics unless the base packages are in identifier order
+// and lead the generated catalog unchanged; DP backtracking depends on item order
+func mustBeInCatalogOrder(base, pkgs []PackageMetadata) {
+	for i := 1; i < len(base); i++ {
+		if base[i-1].Identifier >= base[i].Identifier {
+			panic(fmt.Sprintf("catalog order: base package %s at index %d follows %s", base[i].Identifier, i, base[i-1].Identifier))
+		}
+	}
+	if len(pkgs) < len(base) {
+		panic(fmt.Sprintf("catalog order: generated %d packages, fewer than the %d base packages", len(pkgs), len(base)))
+	}
+	for i, b := range base {
+		if p := pkgs[i]; p.Identifier != b.Identifier || p.MassConstraint != b.MassConstraint || p.Valuation != b.Valuation {
+			panic(fmt.Sprintf("catalog order: generated package %d is %s, want base package %s", i, pkgs[i].Identifier, b.Identifier))
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSeedRange(t *testing.T) {
+	generator := NewEmailBasedPackageGenerator()
+	for i := 0; i < 2000; i++ {
+		email := fmt.Sprintf("user%d@example%d.com", i, i%7)
+		for _, weight := range []uint64{1, 3} {
+			generator.DomainWeight = weight
+			if err := CheckSeedRange(generator.basePackages, generator.Generate(email)); err != nil {
+				t.Fatalf("%s, domain weight %d: %v", email, weight, err)
+			}
+		}
+	}
+
+	// every out-of-range attribute is reported with its value
+	pkgs := NewEmailBasedPackageGenerator().Generate("test@example.com")
+	base := len(generator.basePackages)
+	pkgs[base].MassConstraint = 20
+	pkgs[base+1].Valuation = 49
+	err := CheckSeedRange(generator.basePackages, pkgs)
+	for _, want := range []string{"X mass 20 outside [5, 19]", "Y value 49 outside [50, 89]"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want it to mention %q", err, want)
+		}
+	}
+	if err := CheckSeedRange(generator.basePackages, pkgs[:base+1]); err == nil {
+		t.Error("a catalog without Y passed the seed range check")
+	}
+
+	opts, err := parseOptions([]string{"test@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.checkSeedRange {
+		t.Error("-check-seed-range is off under go test")
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// logLevel controls which diagnostics reach stderr
+type logLevel int
+
+const (
+	levelQuiet logLevel = iota
+	levelNormal
+	levelVerbose
+)
+
+// diagLevel is set from -quiet/-verbose; -quiet takes precedence when both are given
+var diagLevel = levelNormal
+
+// diagOut receives warnings and requested reports; it discards everything under -quiet
+var diagOut io.Writer = os.Stderr
+
+// errOut receives errorf's failure messages, which -quiet does not silence
+var errOut io.Writer = os.Stderr
+
+// setDiagLevel applies a diagnostics level; every call resets diagOut, so a
+// -quiet run does not silence the runs after it in the same process
+func setDiagLevel(level logLevel) {
+	diagLevel = level
+	diagOut = os.Stderr
+	if level == levelQuiet {
+		diagOut = io.Discard
+	}
+}
+
+// timestampLayouts maps -time-stamp values to time layouts; none disables stamping
+var timestampLayouts = map[string]string{
+	"rfc3339": time.RFC3339,
+	"ms":      "2006-01-02T15:04:05.000Z07:00",
+	"us":      "2006-01-02T15:04:05.000000Z07:00",
+	"ns":      "2006-01-02T15:04:05.000000000Z07:00",
+	"none":    "",
+}
+
+// timestampHandler is the slog.Handler behind every diagnostic line. It
+// writes the record's message to diagOut, or to errOut for errors, prefixed
+// with the record time in Layout unless Layout is empty. Levels are filtered
+// by warnf, infof and verbosef before logging, and attributes are not used.
+type timestampHandler struct {
+	Layout string
+}
+
+func (h *timestampHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *timestampHandler) Handle(_ context.Context, r slog.Record) error {
+	line := r.Message + "\n"
+	if h.Layout != "" && !r.Time.IsZero() {
+		line = r.Time.Format(h.Layout) + " " + line
+	}
+	out := diagOut
+	if r.Level >= slog.LevelError {
+		out = errOut
+	}
+	_, err := io.WriteString(out, line)
+	return err
+}
+
+func (h *timestampHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *timestampHandler) WithGroup(string) slog.Handler      { return h }
+
+// diagLogger writes the diagnostic lines; -time-stamp sets its layout
+var diagLogger = slog.New(&timestampHandler{Layout: time.RFC3339})
+
+// setDiagTimestamp selects the -time-stamp format
+func setDiagTimestamp(name string) error {
+	layout, ok := timestampLayouts[name]
+	if !ok {
+		names := make([]string, 0, len(timestampLayouts))
+		for n := range timestampLayouts {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown time-stamp format %q (want %s)", name, strings.Join(names, ", "))
+	}
+	diagLogger = slog.New(&timestampHandler{Layout: layout})
+	return nil
+}
+
+// logf writes one diagnostic line through diagLogger
+func logf(format string, args ...any) {
+	diagLogger.Info(fmt.Sprintf(format, args...))
+}
+
+// errorf reports a failure to stderr, whatever the diagnostics level
+func errorf(format string, args ...any) {
+	diagLogger.Error(fmt.Sprintf(format, args...))
+}
+
+// warnf prints a warning to stderr unless -quiet is set
+func warnf(format string, args ...any) {
+	if diagLevel >= levelNormal {
+		logf("warning: "+format, args...)
+	}
+}
+
+// infof prints a progress message to stderr unless -quiet is set
+func infof(format string, args ...any) {
+	if diagLevel >= levelNormal {
+		logf(format, args...)
+	}
+}
+
+// verbosef prints a diagnostic to stderr only when -verbose is set
+func verbosef(format string, args ...any) {
+	if diagLevel >= levelVerbose {
+		logf(format, args...)
+	}
+}
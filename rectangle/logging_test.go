@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDiagTimestamp(t *testing.T) {
+	out, logger := diagOut, diagLogger
+	t.Cleanup(func() { diagOut, diagLogger = out, logger })
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"rfc3339", `^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(Z|[+-]\d\d:\d\d) hello 7\n$`},
+		{"ms", `^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}(Z|[+-]\d\d:\d\d) hello 7\n$`},
+		{"us", `^\S+:\d\d\.\d{6}(Z|[+-]\d\d:\d\d) hello 7\n$`},
+		{"ns", `^\S+:\d\d\.\d{9}(Z|[+-]\d\d:\d\d) hello 7\n$`},
+		{"none", `^hello 7\n$`},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		diagOut = &buf
+		if err := setDiagTimestamp(tt.name); err != nil {
+			t.Fatal(err)
+		}
+		logf("hello %d", 7)
+		if !regexp.MustCompile(tt.want).MatchString(buf.String()) {
+			t.Errorf("-time-stamp %s: logged %q, want %s", tt.name, buf.String(), tt.want)
+		}
+	}
+	if err := setDiagTimestamp("s"); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestDiagTimestampDefault(t *testing.T) {
+	opts, err := parseOptions([]string{"test@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.timeStamp != "rfc3339" {
+		t.Errorf("-time-stamp defaults to %q, want rfc3339", opts.timeStamp)
+	}
+}
+
+func TestQuietDoesNotOutliveItsRun(t *testing.T) {
+	level, out := diagLevel, diagOut
+	t.Cleanup(func() { diagLevel, diagOut = level, out })
+
+	if _, err := parseOptions([]string{"-quiet", "test@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseOptions([]string{"test@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if diagLevel != levelNormal || diagOut != io.Writer(os.Stderr) {
+		t.Errorf("after a -quiet parse, a plain parse left level %d and diagOut %T", diagLevel, diagOut)
+	}
+}
+
+func TestQuietKeepsErrors(t *testing.T) {
+	level, out, errs := diagLevel, diagOut, errOut
+	t.Cleanup(func() { diagLevel, diagOut, errOut = level, out, errs })
+
+	var buf bytes.Buffer
+	errOut = &buf
+	setDiagLevel(levelQuiet)
+	warnf("a warning")
+	errorf("an error")
+	if got := buf.String(); !strings.HasSuffix(got, "an error\n") || strings.Contains(got, "warning") {
+		t.Errorf("-quiet reported %q, want only the error", got)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// LPItem is one row of the fractional knapsack relaxation
+type LPItem struct {
+	Package  PackageMetadata
+	Ratio    float64
+	Fraction float64 // share of the package taken, in [0, 1]
+}
+
+// lpRelaxation solves the fractional knapsack: fill by value density, taking
+// a fraction of the first package that no longer fits
+func lpRelaxation(pkgs []PackageMetadata, maxLoad int) ([]LPItem, float64) {
+	sorted := make([]PackageMetadata, len(pkgs))
+	copy(sorted, pkgs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		// compare Vi/Wi > Vj/Wj without dividing
+		return sorted[i].Valuation*sorted[j].MassConstraint > sorted[j].Valuation*sorted[i].MassConstraint
+	})
+
+	items := make([]LPItem, len(sorted))
+	bound := 0.0
+	remaining := maxLoad
+	for k, p := range sorted {
+		items[k] = LPItem{Package: p, Ratio: ratio(p)}
+		switch {
+		case remaining <= 0:
+		case p.MassConstraint <= remaining:
+			items[k].Fraction = 1
+			bound += float64(p.Valuation)
+			remaining -= p.MassConstraint
+		default:
+			items[k].Fraction = float64(remaining) / float64(p.MassConstraint)
+			bound += float64(p.Valuation) * items[k].Fraction
+			remaining = 0
+		}
+	}
+	return items, bound
+}
+
+// ratio is a package's value per unit mass; massless packages rank first
+func ratio(p PackageMetadata) float64 {
+	if p.MassConstraint == 0 {
+		return float64(p.Valuation)
+	}
+	return float64(p.Valuation) / float64(p.MassConstraint)
+}
+
+// UpperBound is the fractional knapsack optimum, which the integer optimum never
+// exceeds; cheap enough for sanity checks and branch-and-bound pruning
+func UpperBound(pkgs []PackageMetadata, maxLoad int) float64 {
+	_, bound := lpRelaxation(pkgs, maxLoad)
+	return bound
+}
+
+// printLPTableau writes the LP relaxation tableau and its rounding gap to the integer optimum
+func printLPTableau(w io.Writer, pkgs []PackageMetadata, ctx HeuristicContext) {
+	items, bound := lpRelaxation(pkgs, ctx.MaxLoad)
+	fmt.Fprintf(w, "%-4s %6s %6s %8s %8s %9s\n", "item", "mass", "value", "v/w", "taken", "lp value")
+	for _, it := range items {
+		fmt.Fprintf(w, "%-4s %6d %6d %8.3f %8.3f %9.2f\n",
+			it.Package.Identifier, it.Package.MassConstraint, it.Package.Valuation, it.Ratio, it.Fraction, it.Fraction*float64(it.Package.Valuation))
+	}
+	optimum := optimalValue(pkgs, ctx)
+	fmt.Fprintf(w, "LP bound:        %s\n", fnum(bound, 2))
+	fmt.Fprintf(w, "integer optimum: %s\n", num(optimum))
+	fmt.Fprintf(w, "rounding gap:    %s\n", fnum(bound-float64(optimum), 2))
+}
+
+// TopOff fills the capacity left by an integer selection with a fraction of
+// the densest unselected package; ok is false when nothing can be added
+func TopOff(pkgs, selected []PackageMetadata, maxLoad int) (item LPItem, extra float64, ok bool) {
+	remaining := maxLoad - totalMass(selected)
+	if remaining <= 0 {
+		return LPItem{}, 0, false
+	}
+	taken := make(map[string]bool, len(selected))
+	for _, p := range selected {
+		taken[p.Identifier] = true
+	}
+	for _, p := range pkgs {
+		if taken[p.Identifier] || p.Valuation <= 0 {
+			continue
+		}
+		if !ok || ratio(p) > item.Ratio {
+			item, ok = LPItem{Package: p, Ratio: ratio(p)}, true
+		}
+	}
+	if !ok {
+		return LPItem{}, 0, false
+	}
+	item.Fraction = 1
+	if m := item.Package.MassConstraint; m > remaining {
+		item.Fraction = float64(remaining) / float64(m)
+	}
+	return item, item.Fraction * float64(item.Package.Valuation), true
+}
+
+// printTopOff reports the integer value, the fractional top-off and their sum
+func printTopOff(w io.Writer, pkgs, selected []PackageMetadata, maxLoad int) {
+	base := totalValue(selected)
+	fmt.Fprintf(w, "integer value:  %s\n", num(base))
+	item, extra, ok := TopOff(pkgs, selected, maxLoad)
+	if !ok {
+		fmt.Fprintln(w, "top-off:        none (no spare capacity or no unselected package)")
+		return
+	}
+	fmt.Fprintf(w, "top-off:        %s of %s (+%s)\n", fnum(item.Fraction, 3), item.Package.Identifier, fnum(extra, 2))
+	fmt.Fprintf(w, "combined worth: %s\n", fnum(float64(base)+extra, 2))
+}
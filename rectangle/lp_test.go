@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestDPWithinUpperBound(t *testing.T) {
+	for seed := uint64(1); seed <= 25; seed++ {
+		for _, W := range []int{0, 10, 50, 200} {
+			pkgs := appendSynthetic(nil, 12, 60, seed)
+			optimum := totalValue((&PriorityBasedOptimizer{}).Optimize(pkgs, HeuristicContext{MaxLoad: W}))
+			if bound := UpperBound(pkgs, W); float64(optimum) > bound+1e-9 {
+				t.Errorf("seed %d, capacity %d: DP optimum %d exceeds LP upper bound %g", seed, W, optimum, bound)
+			}
+		}
+	}
+}
+
+func TestUpperBoundTightWhenEverythingFits(t *testing.T) {
+	pkgs := NewEmailBasedPackageGenerator().Generate("test@example.com")
+	W := totalMass(pkgs)
+	if bound, total := UpperBound(pkgs, W), totalValue(pkgs); bound != float64(total) {
+		t.Errorf("UpperBound = %g with room for every package, want %d", bound, total)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// confirmThreshold is the DP table size above which interactive runs ask first
+const confirmThreshold = 256 << 20
+
+// byteUnits maps size suffixes accepted by -memory-limit to multipliers
+var byteUnits = []struct {
+	suffix string
+	scale  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseByteSize parses sizes like "100MB", "512KB" or "1048576"
+func parseByteSize(s string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	scale := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(upper, u.suffix) {
+			upper = strings.TrimSpace(strings.TrimSuffix(upper, u.suffix))
+			scale = u.scale
+			break
+		}
+	}
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	return n * scale, nil
+}
+
+// estimateDPBytes is the size of an n-item DP table at capacity W with 8-byte cells
+func estimateDPBytes(n, W int) int64 {
+	return int64(n) * int64(W+1) * 8
+}
+
+// checkMemoryLimit returns an error if the DP table would exceed limit bytes (0 disables the check)
+func checkMemoryLimit(n, W int, limit int64) error {
+	if limit <= 0 {
+		return nil
+	}
+	if need := estimateDPBytes(n, W); need > limit {
+		return fmt.Errorf("DP table needs %s bytes, exceeding the memory limit of %s bytes; reduce -capacity or use -algo greedy or -algo sa",
+			FormatInt(int(need), ','), FormatInt(int(limit), ','))
+	}
+	return nil
+}
+
+// confirmAllocation asks on out whether to allocate a DP table of need bytes
+// and reads the answer from in; anything but y/yes declines
+func confirmAllocation(in io.Reader, out io.Writer, need int64) bool {
+	fmt.Fprintf(out, "This will allocate ~%s MB, continue? [y/N] ", FormatInt(int(need>>20), ','))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// allocationConfirmed prompts before large DP tables when stdin is a terminal;
+// non-interactive runs and -yes proceed without asking
+func allocationConfirmed(n, W int, yes bool) bool {
+	need := estimateDPBytes(n, W)
+	if yes || need <= confirmThreshold || !isTerminal(os.Stdin) {
+		return true
+	}
+	return confirmAllocation(os.Stdin, os.Stderr, need)
+}
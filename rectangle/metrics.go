@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// batchMetrics aggregates a -batch run for -metrics-file. Metric names:
+//
+//	truckload_batch_emails_total                 emails read from the batch input
+//	truckload_batch_optimizations_total          optimizations completed
+//	truckload_batch_failures_total               emails that failed preprocessing or submission
+//	truckload_batch_selected_value_total         sum of the selected value over all emails
+//	truckload_batch_optimization_seconds_total   time spent inside the optimizer
+//	truckload_batch_duration_seconds             wall-clock time of the whole batch
+type batchMetrics struct {
+	emails        int
+	optimizations int
+	failures      int
+	value         int
+	optimizing    time.Duration
+	start         time.Time
+}
+
+// writePrometheus writes the metrics in the Prometheus text exposition format
+func (m *batchMetrics) writePrometheus(w io.Writer) error {
+	metrics := []struct {
+		name, kind, help string
+		value            float64
+	}{
+		{"truckload_batch_emails_total", "counter", "Emails read from the batch input.", float64(m.emails)},
+		{"truckload_batch_optimizations_total", "counter", "Optimizations completed.", float64(m.optimizations)},
+		{"truckload_batch_failures_total", "counter", "Emails that failed preprocessing or submission.", float64(m.failures)},
+		{"truckload_batch_selected_value_total", "counter", "Sum of the selected value over all emails.", float64(m.value)},
+		{"truckload_batch_optimization_seconds_total", "counter", "Time spent inside the optimizer.", m.optimizing.Seconds()},
+		{"truckload_batch_duration_seconds", "gauge", "Wall-clock time of the whole batch.", time.Since(m.start).Seconds()},
+	}
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n",
+			metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMetricsFile writes the batch metrics to path
+func writeMetricsFile(path string, m *batchMetrics) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := m.writePrometheus(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
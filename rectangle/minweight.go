@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+// errTargetUnreachable is reported when no selection within capacity reaches the target value
+var errTargetUnreachable = errors.New("no selection within the capacity reaches the target value")
+
+// MinWeightOptimizer solves the dual knapsack: the lightest selection whose
+// value is at least Target. The DP runs over values instead of capacities:
+// dp[i][v] is the least mass reaching exactly value v with the first i
+// items, for v up to the catalog's total value, so it costs O(n·V).
+type MinWeightOptimizer struct {
+	Target int
+}
+
+// Optimize returns the lightest selection worth at least Target, or nil when
+// even the lightest such selection exceeds the capacity (or none exists)
+func (o *MinWeightOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	const unreachable = math.MaxInt
+	n, V := len(pkgs), totalValue(pkgs)
+	if o.Target > V {
+		return nil
+	}
+	dp := make([][]int, n+1)
+	for i := range dp {
+		dp[i] = make([]int, V+1)
+		for v := range dp[i] {
+			dp[i][v] = unreachable
+		}
+	}
+	dp[0][0] = 0
+	for i := 1; i <= n; i++ {
+		wt, val := pkgs[i-1].MassConstraint, pkgs[i-1].Valuation
+		for v := 0; v <= V; v++ {
+			dp[i][v] = dp[i-1][v]
+			if v >= val && dp[i-1][v-val] != unreachable && dp[i-1][v-val]+wt < dp[i][v] {
+				dp[i][v] = dp[i-1][v-val] + wt
+			}
+		}
+	}
+
+	best := -1
+	for v := max(o.Target, 0); v <= V; v++ {
+		if dp[n][v] != unreachable && (best < 0 || dp[n][v] < dp[n][best]) {
+			best = v
+		}
+	}
+	if best < 0 || dp[n][best] > ctx.MaxLoad {
+		return nil
+	}
+
+	res := []PackageMetadata{}
+	for i, v := n, best; i > 0; i-- {
+		if dp[i][v] != dp[i-1][v] {
+			res = append(res, pkgs[i-1])
+			v -= pkgs[i-1].Valuation
+		}
+	}
+	return res
+}
+
+// EarlyTerminationDP is the capacity DP that stops filling as soon as a row
+// reaches Target. Rows are monotone in w, so row i reaches it exactly when
+// dp[i][W] >= Target; the selection is then recovered from that row using
+// only the first i items. It is any selection worth at least Target, neither
+// the most valuable nor the lightest.
+type EarlyTerminationDP struct {
+	Target int
+	// Rows is the number of item rows filled by the last Optimize
+	Rows int
+}
+
+// Optimize returns a selection worth at least Target, or nil if the full
+// table never reaches it
+func (o *EarlyTerminationDP) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	W := ctx.MaxLoad
+	dp := newDenseTable(len(pkgs)+1, W+1).(denseTable)
+	o.Rows = 0
+	for i := 1; i <= len(pkgs); i++ {
+		o.Rows = i
+		wt, val := pkgs[i-1].MassConstraint, pkgs[i-1].Valuation
+		for w := 0; w <= W; w++ {
+			dp[i][w] = dp[i-1][w]
+			if wt <= w && dp[i-1][w-wt]+val > dp[i][w] {
+				dp[i][w] = dp[i-1][w-wt] + val
+			}
+		}
+		if dp[i][W] >= o.Target {
+			// recover from the least capacity that already reaches the target
+			w := W
+			for w > 0 && dp[i][w-1] >= o.Target {
+				w--
+			}
+			return backtrack(dp[:i+1], pkgs[:i], w)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestEarlyTerminationReachesTarget(t *testing.T) {
+	for seed := uint64(1); seed <= 20; seed++ {
+		pkgs := appendSynthetic(nil, 15, 30, seed)
+		ctx := HeuristicContext{MaxLoad: 60}
+		optimum := totalValue((&PriorityBasedOptimizer{}).Optimize(pkgs, ctx))
+		for _, target := range []int{1, optimum / 4, optimum / 2, optimum} {
+			early := &EarlyTerminationDP{Target: target}
+			selected := early.Optimize(pkgs, ctx)
+			if selected == nil {
+				t.Errorf("seed %d, target %d: nil, but the optimum is %d", seed, target, optimum)
+				continue
+			}
+			if m, v := totalMass(selected), totalValue(selected); m > ctx.MaxLoad || v < target {
+				t.Errorf("seed %d, target %d: mass %d, value %d; want mass <= %d and value >= target", seed, target, m, v, ctx.MaxLoad)
+			}
+			if early.Rows > len(pkgs) {
+				t.Errorf("seed %d, target %d: %d rows filled of %d", seed, target, early.Rows, len(pkgs))
+			}
+		}
+		if selected := (&EarlyTerminationDP{Target: optimum + 1}).Optimize(pkgs, ctx); selected != nil {
+			t.Errorf("seed %d: target above the optimum %d returned %v", seed, optimum, identifiers(selected))
+		}
+	}
+}
+
+func TestEarlyTerminationStopsEarly(t *testing.T) {
+	pkgs := []PackageMetadata{
+		{Identifier: "A", MassConstraint: 5, Valuation: 100},
+		{Identifier: "B", MassConstraint: 5, Valuation: 10},
+		{Identifier: "C", MassConstraint: 5, Valuation: 10},
+	}
+	early := &EarlyTerminationDP{Target: 50}
+	selected := early.Optimize(pkgs, HeuristicContext{MaxLoad: 20})
+	if early.Rows != 1 || len(selected) != 1 || selected[0].Identifier != "A" {
+		t.Errorf("filled %d rows and selected %v, want 1 row and [A]", early.Rows, identifiers(selected))
+	}
+}
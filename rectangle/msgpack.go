@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// MarshalMsgpack encodes a result as a MessagePack map using the JSON field names:
+//
+//	email             str
+//	algorithm         str
+//	capacity          int
+//	selected          array of str
+//	total_mass        int
+//	total_value       int
+//	approximate       bool (only when true)
+//	optimal_count     int (only with -count-optimal-solutions)
+//	package_set_hash  str (only when set)
+//	stats             map (only with -include-stats): dp_cells_computed int,
+//	                  backtrack_steps int, total_weight int,
+//	                  value_to_capacity_ratio float64, lp_bound float64,
+//	                  optimality_gap float64
+func MarshalMsgpack(r OptimizationResult) []byte {
+	fields := 6
+	for _, set := range []bool{r.Approximate, r.OptimalCount != 0, r.PackageSetHash != "", r.Stats != nil} {
+		if set {
+			fields++
+		}
+	}
+	b := appendMsgpackMap(nil, fields)
+	b = appendMsgpackString(appendMsgpackString(b, "email"), r.Email)
+	b = appendMsgpackString(appendMsgpackString(b, "algorithm"), r.Algorithm)
+	b = appendMsgpackInt(appendMsgpackString(b, "capacity"), int64(r.Capacity))
+	b = appendMsgpackArray(appendMsgpackString(b, "selected"), len(r.Selected))
+	for _, id := range r.Selected {
+		b = appendMsgpackString(b, id)
+	}
+	b = appendMsgpackInt(appendMsgpackString(b, "total_mass"), int64(r.TotalMass))
+	b = appendMsgpackInt(appendMsgpackString(b, "total_value"), int64(r.TotalValue))
+	if r.Approximate {
+		b = appendMsgpackBool(appendMsgpackString(b, "approximate"), true)
+	}
+	if r.OptimalCount != 0 {
+		b = appendMsgpackInt(appendMsgpackString(b, "optimal_count"), r.OptimalCount)
+	}
+	if r.PackageSetHash != "" {
+		b = appendMsgpackString(appendMsgpackString(b, "package_set_hash"), r.PackageSetHash)
+	}
+
+	if s := r.Stats; s != nil {
+		b = appendMsgpackMap(appendMsgpackString(b, "stats"), 6)
+		b = appendMsgpackInt(appendMsgpackString(b, "dp_cells_computed"), int64(s.DPCellsComputed))
+		b = appendMsgpackInt(appendMsgpackString(b, "backtrack_steps"), int64(s.BacktrackSteps))
+		b = appendMsgpackInt(appendMsgpackString(b, "total_weight"), int64(s.TotalWeight))
+		b = appendMsgpackFloat(appendMsgpackString(b, "value_to_capacity_ratio"), s.ValueToCapacityRatio)
+		b = appendMsgpackFloat(appendMsgpackString(b, "lp_bound"), s.LPBound)
+		b = appendMsgpackFloat(appendMsgpackString(b, "optimality_gap"), s.OptimalityGap)
+	}
+	return b
+}
+
+func appendMsgpackMap(b []byte, n int) []byte {
+	if n < 16 {
+		return append(b, 0x80|byte(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+}
+
+func appendMsgpackArray(b []byte, n int) []byte {
+	if n < 16 {
+		return append(b, 0x90|byte(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n < 1<<8:
+		b = append(b, 0xd9, byte(n))
+	case n < 1<<16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendMsgpackInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0 && v < 128:
+		return append(b, byte(v))
+	case v < 0 && v >= -32:
+		return append(b, byte(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+func appendMsgpackBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+func appendMsgpackFloat(b []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(f))
+}
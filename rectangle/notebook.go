@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// markdownCell is an nbformat 4 markdown cell
+type markdownCell struct {
+	CellType string         `json:"cell_type"`
+	Metadata map[string]any `json:"metadata"`
+	Source   []string       `json:"source"`
+}
+
+// codeCell is an unexecuted nbformat 4 code cell; execution_count must be null
+type codeCell struct {
+	CellType       string         `json:"cell_type"`
+	Metadata       map[string]any `json:"metadata"`
+	Source         []string       `json:"source"`
+	Outputs        []any          `json:"outputs"`
+	ExecutionCount *int           `json:"execution_count"`
+}
+
+// notebook is the top-level nbformat 4 document
+type notebook struct {
+	Cells         []any          `json:"cells"`
+	Metadata      map[string]any `json:"metadata"`
+	NBFormat      int            `json:"nbformat"`
+	NBFormatMinor int            `json:"nbformat_minor"`
+}
+
+// cellSource splits text into notebook source lines, keeping the newlines
+func cellSource(text string) []string {
+	return strings.SplitAfter(text, "\n")
+}
+
+func newMarkdownCell(text string) markdownCell {
+	return markdownCell{CellType: "markdown", Metadata: map[string]any{}, Source: cellSource(text)}
+}
+
+func newCodeCell(text string) codeCell {
+	return codeCell{CellType: "code", Metadata: map[string]any{}, Source: cellSource(text), Outputs: []any{}}
+}
+
+// buildNotebook lays out the knapsack instance as a teaching notebook: data,
+// chart, LaTeX formulation and a PuLP model that solves it
+func buildNotebook(pkgs []PackageMetadata, ctx HeuristicContext, email string) notebook {
+	var rows strings.Builder
+	for _, p := range pkgs {
+		fmt.Fprintf(&rows, "    (%q, %d, %d),\n", p.Identifier, p.MassConstraint, p.Valuation)
+	}
+
+	cells := []any{
+		newMarkdownCell(fmt.Sprintf("# Truck loading as a 0/1 knapsack\n\nPackages generated for `%s`, truck capacity %d.", email, ctx.MaxLoad)),
+		newCodeCell("import pandas as pd\n\n" +
+			"packages = pd.DataFrame([\n" + rows.String() + "], columns=[\"id\", \"mass\", \"value\"])\n" +
+			fmt.Sprintf("capacity = %d\n", ctx.MaxLoad) +
+			"packages"),
+		newCodeCell("import matplotlib.pyplot as plt\n\n" +
+			"ax = packages.set_index(\"id\")[[\"mass\", \"value\"]].plot.bar(figsize=(8, 4))\n" +
+			"ax.set_ylabel(\"units\")\n" +
+			"ax.set_title(\"Package mass and value\")\n" +
+			"plt.show()"),
+		newMarkdownCell("## Formulation\n\n" +
+			"With $x_i \\in \\{0, 1\\}$ indicating whether package $i$ is loaded:\n\n" +
+			"$$\\max \\sum_{i=1}^{n} v_i x_i \\quad \\text{s.t.} \\quad \\sum_{i=1}^{n} w_i x_i \\le W$$\n\n" +
+			"The dynamic program fills $dp[i][w]$, the best value from the first $i$ packages within capacity $w$:\n\n" +
+			"$$dp[i][w] = \\begin{cases} dp[i-1][w] & w_i > w \\\\ \\max(dp[i-1][w],\\ dp[i-1][w-w_i] + v_i) & \\text{otherwise} \\end{cases}$$"),
+		newCodeCell("import pulp\n\n" +
+			"model = pulp.LpProblem(\"truck_loading\", pulp.LpMaximize)\n" +
+			"x = {r.id: pulp.LpVariable(f\"x_{r.id}\", cat=\"Binary\") for r in packages.itertuples()}\n" +
+			"model += pulp.lpSum(r.value * x[r.id] for r in packages.itertuples())\n" +
+			"model += pulp.lpSum(r.mass * x[r.id] for r in packages.itertuples()) <= capacity\n" +
+			"model.solve(pulp.PULP_CBC_CMD(msg=False))\n\n" +
+			"selected = sorted(i for i, var in x.items() if var.value() == 1)\n" +
+			"print(\"selected:\", \",\".join(selected))\n" +
+			"print(\"value:\", pulp.value(model.objective))"),
+	}
+	return notebook{
+		Cells: cells,
+		Metadata: map[string]any{
+			"kernelspec":    map[string]string{"name": "python3", "display_name": "Python 3", "language": "python"},
+			"language_info": map[string]string{"name": "python"},
+		},
+		NBFormat:      4,
+		NBFormatMinor: 4,
+	}
+}
+
+// exportNotebook writes the instance as a Jupyter notebook to path
+func exportNotebook(path string, pkgs []PackageMetadata, ctx HeuristicContext, email string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", " ")
+	if err := enc.Encode(buildNotebook(pkgs, ctx, email)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
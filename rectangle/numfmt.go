@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// humanNumbers enables digit grouping in reports (-human-readable-numbers)
+var humanNumbers bool
+
+// FormatInt formats n with sep between groups of three digits, e.g. 1,000,000
+func FormatInt(n int, sep rune) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= 3 {
+		return sign + digits
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if i > 0 {
+			b.WriteRune(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// FormatFloat formats f with prec decimals, grouping the integer part with sep
+func FormatFloat(f float64, prec int, sep rune) string {
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+	n, err := strconv.Atoi(intPart)
+	if err != nil {
+		return s
+	}
+	grouped := FormatInt(n, sep)
+	if intPart == "-0" {
+		grouped = "-0"
+	}
+	if hasFrac {
+		return grouped + "." + frac
+	}
+	return grouped
+}
+
+// num formats an integer for reports, honouring -human-readable-numbers
+func num(n int) string {
+	if humanNumbers {
+		return FormatInt(n, ',')
+	}
+	return strconv.Itoa(n)
+}
+
+// fnum formats a float with prec decimals for reports, honouring -human-readable-numbers
+func fnum(f float64, prec int) string {
+	if humanNumbers {
+		return FormatFloat(f, prec, ',')
+	}
+	return strconv.FormatFloat(f, 'f', prec, 64)
+}
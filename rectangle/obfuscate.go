@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// RandomizedIDOptimizer hides the package identifiers from Inner: every
+// package, dependency and exclusion is renamed to a random string drawn
+// from Seed, and the selection is mapped back to the original packages
+type RandomizedIDOptimizer struct {
+	Inner LoadOptimizer
+	Seed  int64
+}
+
+// Optimize runs Inner on the renamed catalog
+func (o *RandomizedIDOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	rng := rand.New(rand.NewSource(o.Seed))
+	alias := make(map[string]string, len(pkgs))
+	original := make(map[string]PackageMetadata, len(pkgs))
+	taken := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		id := fmt.Sprintf("%08x", rng.Uint32())
+		for taken[id] {
+			id = fmt.Sprintf("%08x", rng.Uint32())
+		}
+		taken[id] = true
+		alias[p.Identifier] = id
+		original[id] = p
+	}
+	rename := func(ids []string) []string {
+		if ids == nil {
+			return nil
+		}
+		out := make([]string, len(ids))
+		for i, id := range ids {
+			if a, ok := alias[id]; ok {
+				out[i] = a
+			} else {
+				out[i] = id
+			}
+		}
+		return out
+	}
+
+	renamed := make([]PackageMetadata, len(pkgs))
+	for i, p := range pkgs {
+		renamed[i] = p
+		renamed[i].Identifier = alias[p.Identifier]
+		renamed[i].Dependencies = rename(p.Dependencies)
+		renamed[i].ExcludedBy = rename(p.ExcludedBy)
+	}
+	selected := o.Inner.Optimize(renamed, ctx)
+	if selected == nil {
+		return nil
+	}
+	out := make([]PackageMetadata, len(selected))
+	for i, p := range selected {
+		out[i] = original[p.Identifier]
+	}
+	return out
+}
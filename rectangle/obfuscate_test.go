@@ -0,0 +1,54 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// recordingOptimizer remembers the identifiers it was asked to optimize
+type recordingOptimizer struct {
+	Inner LoadOptimizer
+	seen  []string
+}
+
+func (o *recordingOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	o.seen = identifiers(pkgs)
+	return o.Inner.Optimize(pkgs, ctx)
+}
+
+func TestRandomizedIDsMatchPlainRun(t *testing.T) {
+	generator := NewEmailBasedPackageGenerator()
+	for _, email := range []string{"test@example.com", "a@b.c", "someone@example.org"} {
+		pkgs := generator.Generate(email)
+		for _, W := range []int{10, 50, 120} {
+			ctx := HeuristicContext{MaxLoad: W, PriorityFactor: 1.0}
+			for name, inner := range map[string]func() LoadOptimizer{
+				"dp":     func() LoadOptimizer { return &PriorityBasedOptimizer{} },
+				"greedy": func() LoadOptimizer { return &GreedyOptimizer{} },
+			} {
+				want := identifiers(inner().Optimize(pkgs, ctx))
+				for _, seed := range []int64{1, 42} {
+					rec := &recordingOptimizer{Inner: inner()}
+					got := identifiers((&RandomizedIDOptimizer{Inner: rec, Seed: seed}).Optimize(pkgs, ctx))
+					if !slices.Equal(got, want) {
+						t.Errorf("%s, capacity %d, %s, seed %d: selected %v, want %v", email, W, name, seed, got, want)
+					}
+					for _, id := range rec.seen {
+						if slices.Contains(identifiers(pkgs), id) {
+							t.Errorf("%s, seed %d: optimizer saw original identifier %s", email, seed, id)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// the same seed hides the catalog behind the same names
+	pkgs := generator.Generate("test@example.com")
+	a, b := &recordingOptimizer{Inner: &PriorityBasedOptimizer{}}, &recordingOptimizer{Inner: &PriorityBasedOptimizer{}}
+	(&RandomizedIDOptimizer{Inner: a, Seed: 7}).Optimize(pkgs, HeuristicContext{MaxLoad: 50})
+	(&RandomizedIDOptimizer{Inner: b, Seed: 7}).Optimize(pkgs, HeuristicContext{MaxLoad: 50})
+	if !slices.Equal(a.seen, b.seen) {
+		t.Errorf("seed 7 renamed to %v and then %v", a.seen, b.seen)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParetoFront returns the capacities at which the optimum improves: reading
+// dp[n][w] upward, each w whose value beats dp[n][w-1] is a Pareto-optimal
+// (mass, value) trade-off, since no lighter selection is worth as much
+func ParetoFront(pkgs []PackageMetadata, maxLoad int) []SweepPoint {
+	dp := buildTable(pkgs, maxLoad)
+	n := len(pkgs)
+	var points []SweepPoint
+	for w := 0; w <= maxLoad; w++ {
+		if w == 0 || dp[n][w] > dp[n][w-1] {
+			points = append(points, SweepPoint{Capacity: w, Selected: backtrack(dp, pkgs, w)})
+		}
+	}
+	return points
+}
+
+// writeParetoCSV writes capacity,mass,value,selected rows
+func writeParetoCSV(w io.Writer, points []SweepPoint) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"capacity", "mass", "value", "selected"})
+	for _, p := range points {
+		ids := identifiers(p.Selected)
+		sort.Strings(ids)
+		cw.Write([]string{
+			strconv.Itoa(p.Capacity),
+			strconv.Itoa(totalMass(p.Selected)),
+			strconv.Itoa(totalValue(p.Selected)),
+			strings.Join(ids, " "),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportParetoFront writes the Pareto front CSV for pkgs to path
+func exportParetoFront(path string, pkgs []PackageMetadata, maxLoad int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeParetoCSV(f, ParetoFront(pkgs, maxLoad)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+// errParityInfeasible is returned when no selection has the requested mass parity
+var errParityInfeasible = errors.New("no selection with the requested total mass parity fits the capacity")
+
+// ParityOptimizer maximizes value subject to the capacity and to the total
+// selected mass being even (Parity 0) or odd (Parity 1). The DP carries an
+// extra parity dimension: dp[i][w][p] is the best value using the first i
+// items with mass at most w and mass parity p.
+type ParityOptimizer struct {
+	Parity int
+}
+
+// Optimize returns the best selection with the required parity, or nil when
+// none exists (an empty but feasible selection is returned as a non-nil slice)
+func (o *ParityOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	const unreachable = math.MinInt
+	n, W := len(pkgs), ctx.MaxLoad
+	dp := make([][][2]int, n+1)
+	for i := range dp {
+		dp[i] = make([][2]int, W+1)
+	}
+	for w := 0; w <= W; w++ {
+		dp[0][w] = [2]int{0, unreachable}
+	}
+
+	for i := 1; i <= n; i++ {
+		wt, val := pkgs[i-1].MassConstraint, pkgs[i-1].Valuation
+		for w := 0; w <= W; w++ {
+			for p := 0; p < 2; p++ {
+				best := dp[i-1][w][p]
+				if wt <= w {
+					if prev := dp[i-1][w-wt][p^(wt&1)]; prev != unreachable && prev+val > best {
+						best = prev + val
+					}
+				}
+				dp[i][w][p] = best
+			}
+		}
+	}
+
+	p := o.Parity & 1
+	if dp[n][W][p] == unreachable {
+		return nil
+	}
+	res := []PackageMetadata{}
+	w := W
+	for i := n; i > 0; i-- {
+		wt, val := pkgs[i-1].MassConstraint, pkgs[i-1].Valuation
+		if dp[i][w][p] == dp[i-1][w][p] {
+			continue
+		}
+		if wt <= w && dp[i-1][w-wt][p^(wt&1)] != unreachable && dp[i][w][p] == dp[i-1][w-wt][p^(wt&1)]+val {
+			res = append(res, pkgs[i-1])
+			w -= wt
+			p ^= wt & 1
+		}
+	}
+	return res
+}
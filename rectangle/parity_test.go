@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestParityOptimizer(t *testing.T) {
+	mixed := []PackageMetadata{
+		{Identifier: "A", MassConstraint: 3, Valuation: 10},
+		{Identifier: "B", MassConstraint: 5, Valuation: 12},
+		{Identifier: "C", MassConstraint: 4, Valuation: 7},
+	}
+	allEven := []PackageMetadata{
+		{Identifier: "A", MassConstraint: 2, Valuation: 5},
+		{Identifier: "B", MassConstraint: 4, Valuation: 7},
+	}
+	tests := []struct {
+		name       string
+		pkgs       []PackageMetadata
+		capacity   int
+		parity     int
+		infeasible bool
+		wantValue  int
+	}{
+		{name: "even", pkgs: mixed, capacity: 8, parity: 0, wantValue: 22},      // A+B, mass 8
+		{name: "odd", pkgs: mixed, capacity: 8, parity: 1, wantValue: 17},       // A+C, mass 7
+		{name: "even empty", pkgs: mixed, capacity: 2, parity: 0, wantValue: 0}, // nothing fits; 0 is even
+		{name: "odd infeasible", pkgs: allEven, capacity: 10, parity: 1, infeasible: true},
+		{name: "odd nothing fits", pkgs: mixed, capacity: 2, parity: 1, infeasible: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := (&ParityOptimizer{Parity: tt.parity}).Optimize(tt.pkgs, HeuristicContext{MaxLoad: tt.capacity})
+			if tt.infeasible {
+				if selected != nil {
+					t.Errorf("selected %v, want nil", identifiers(selected))
+				}
+				return
+			}
+			if selected == nil {
+				t.Fatal("selected nil, want a feasible selection")
+			}
+			if m := totalMass(selected); m > tt.capacity || m%2 != tt.parity {
+				t.Errorf("mass %d, want parity %d within %d", m, tt.parity, tt.capacity)
+			}
+			if v := totalValue(selected); v != tt.wantValue {
+				t.Errorf("value %d, want %d", v, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestParityOptimizerMatchesBruteForce(t *testing.T) {
+	for seed := uint64(1); seed <= 20; seed++ {
+		pkgs := appendSynthetic(nil, 10, 20, seed)
+		for parity := 0; parity < 2; parity++ {
+			want, feasible := 0, false
+			for mask := 0; mask < 1<<len(pkgs); mask++ {
+				mass, value := 0, 0
+				for i, p := range pkgs {
+					if mask&(1<<i) != 0 {
+						mass, value = mass+p.MassConstraint, value+p.Valuation
+					}
+				}
+				if mass <= 40 && mass%2 == parity && (!feasible || value > want) {
+					want, feasible = value, true
+				}
+			}
+			selected := (&ParityOptimizer{Parity: parity}).Optimize(pkgs, HeuristicContext{MaxLoad: 40})
+			if (selected != nil) != feasible || totalValue(selected) != want {
+				t.Errorf("seed %d, parity %d: value %d (nil %v), want %d (feasible %v)", seed, parity, totalValue(selected), selected == nil, want, feasible)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// maxTokenLen bounds the token read by -stdin-password
+const maxTokenLen = 4096
+
+// setEcho turns terminal echo for f on or off with stty, which keeps this
+// dependency-free at the cost of needing stty on the PATH
+func setEcho(f *os.File, on bool) error {
+	arg := "-echo"
+	if on {
+		arg = "echo"
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = f
+	return cmd.Run()
+}
+
+// readToken reads one line from r a byte at a time into a fixed buffer, so no
+// stray copies of the secret are left behind by buffering or slice growth
+func readToken(r io.Reader) ([]byte, error) {
+	buf := make([]byte, 0, maxTokenLen)
+	var b [1]byte
+	for {
+		n, err := r.Read(b[:])
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			if len(buf) == maxTokenLen {
+				clear(buf)
+				return nil, fmt.Errorf("token longer than %d bytes", maxTokenLen)
+			}
+			buf = append(buf, b[0])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			clear(buf)
+			return nil, err
+		}
+	}
+	buf = bytes.TrimSuffix(buf, []byte("\r"))
+	if len(buf) == 0 {
+		return nil, errors.New("empty token")
+	}
+	return buf, nil
+}
+
+// readPassword reads the API token from stdin, prompting on stderr with
+// echo disabled when stdin is a terminal. The caller must clear the result.
+func readPassword() ([]byte, error) {
+	if !isTerminal(os.Stdin) {
+		return readToken(os.Stdin)
+	}
+	fmt.Fprint(os.Stderr, "API token: ")
+	if err := setEcho(os.Stdin, false); err != nil {
+		return nil, fmt.Errorf("disable echo: %w", err)
+	}
+	token, err := readToken(os.Stdin)
+	setEcho(os.Stdin, true)
+	fmt.Fprintln(os.Stderr)
+	return token, err
+}
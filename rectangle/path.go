@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// PathStep is one item in the optimal loading sequence
+type PathStep struct {
+	Package         PackageMetadata
+	Capacity        int // capacity in use once this item is loaded
+	CumulativeValue int
+}
+
+// OptimalPath returns the DP selection ordered by the capacity each item unlocks
+func OptimalPath(pkgs []PackageMetadata, maxLoad int) []PathStep {
+	dp := buildTable(pkgs, maxLoad)
+	selected := backtrack(dp, pkgs, maxLoad)
+
+	// Backtracking walks down from full capacity, so the last item it recovers
+	// occupies the lowest capacity band and is loaded first
+	steps := make([]PathStep, len(selected))
+	used, value := 0, 0
+	for k := range selected {
+		pkg := selected[len(selected)-1-k]
+		used += pkg.MassConstraint
+		value += pkg.Valuation
+		steps[k] = PathStep{Package: pkg, Capacity: used, CumulativeValue: value}
+	}
+	return steps
+}
+
+// printOptimalPath writes the loading sequence one step per line
+func printOptimalPath(w io.Writer, steps []PathStep) {
+	for k, s := range steps {
+		fmt.Fprintf(w, "step %d: load %s (mass=%s value=%s) -> capacity %s, value %s\n",
+			k+1, s.Package.Identifier, num(s.Package.MassConstraint), num(s.Package.Valuation), num(s.Capacity), num(s.CumulativeValue))
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// PerturbationReport summarizes optimizing under random weight noise
+type PerturbationReport struct {
+	Runs             int
+	BaselineValue    int
+	MostFrequent     string  // sorted comma-joined identifiers
+	MostFrequentRuns int     // runs that selected MostFrequent
+	BaselineFeasible float64 // fraction of runs where the baseline set still fits
+	MeanDegradation  float64 // mean of baseline value minus the perturbed optimum
+}
+
+// PerturbWeights optimizes runs times, each time adding independent uniform
+// integer noise in [-delta, delta] to every package mass (clamped at zero)
+func PerturbWeights(pkgs []PackageMetadata, delta, runs int, optimizer LoadOptimizer, ctx HeuristicContext, rng *rand.Rand) PerturbationReport {
+	baseline := optimizer.Optimize(pkgs, ctx)
+	inBaseline := make(map[string]bool, len(baseline))
+	for _, p := range baseline {
+		inBaseline[p.Identifier] = true
+	}
+	report := PerturbationReport{Runs: runs, BaselineValue: totalValue(baseline)}
+
+	counts := make(map[string]int)
+	feasible, degradation := 0, 0
+	perturbed := make([]PackageMetadata, len(pkgs))
+	for run := 0; run < runs; run++ {
+		baselineMass := 0
+		for i, p := range pkgs {
+			p.MassConstraint = max(0, p.MassConstraint+rng.Intn(2*delta+1)-delta)
+			perturbed[i] = p
+			if inBaseline[p.Identifier] {
+				baselineMass += p.MassConstraint
+			}
+		}
+		if baselineMass <= ctx.MaxLoad {
+			feasible++
+		}
+		selected := optimizer.Optimize(perturbed, ctx)
+		degradation += report.BaselineValue - totalValue(selected)
+
+		ids := make([]string, len(selected))
+		for i, p := range selected {
+			ids[i] = p.Identifier
+		}
+		sort.Strings(ids)
+		counts[strings.Join(ids, ",")]++
+	}
+
+	for key, n := range counts {
+		if n > report.MostFrequentRuns || n == report.MostFrequentRuns && key < report.MostFrequent {
+			report.MostFrequent, report.MostFrequentRuns = key, n
+		}
+	}
+	if runs > 0 {
+		report.BaselineFeasible = float64(feasible) / float64(runs)
+		report.MeanDegradation = float64(degradation) / float64(runs)
+	}
+	return report
+}
+
+// printPerturbation writes the weight perturbation report
+func printPerturbation(w io.Writer, r PerturbationReport) {
+	fmt.Fprintf(w, "runs:                %s\n", num(r.Runs))
+	fmt.Fprintf(w, "baseline value:      %s\n", num(r.BaselineValue))
+	fmt.Fprintf(w, "most frequent set:   %s (%s runs)\n", r.MostFrequent, num(r.MostFrequentRuns))
+	fmt.Fprintf(w, "baseline feasible:   %s%%\n", fnum(100*r.BaselineFeasible, 1))
+	fmt.Fprintf(w, "mean degradation:    %s\n", fnum(r.MeanDegradation, 2))
+}
+
+// unavailabilityRuns is the number of -catalog-simulate-unavailability runs
+const unavailabilityRuns = 10
+
+// UnavailabilityReport summarizes optimizing with packages randomly out of stock
+type UnavailabilityReport struct {
+	Runs          int
+	Rate          float64
+	BaselineValue int
+	MeanLoss      float64
+	WorstLoss     int
+	WorstMissing  []string // packages unavailable in the worst run
+}
+
+// SimulateUnavailability optimizes runs times, each time dropping every
+// package independently with probability rate
+func SimulateUnavailability(pkgs []PackageMetadata, rate float64, runs int, optimizer LoadOptimizer, ctx HeuristicContext, rng *rand.Rand) UnavailabilityReport {
+	report := UnavailabilityReport{Runs: runs, Rate: rate, BaselineValue: totalValue(optimizer.Optimize(pkgs, ctx))}
+	total := 0
+	for run := 0; run < runs; run++ {
+		available := make([]PackageMetadata, 0, len(pkgs))
+		var missing []string
+		for _, p := range pkgs {
+			if rng.Float64() < rate {
+				missing = append(missing, p.Identifier)
+				continue
+			}
+			available = append(available, p)
+		}
+		loss := report.BaselineValue - totalValue(optimizer.Optimize(available, ctx))
+		total += loss
+		if run == 0 || loss > report.WorstLoss {
+			report.WorstLoss, report.WorstMissing = loss, missing
+		}
+	}
+	if runs > 0 {
+		report.MeanLoss = float64(total) / float64(runs)
+	}
+	return report
+}
+
+// printUnavailability writes the unavailability simulation report
+func printUnavailability(w io.Writer, r UnavailabilityReport) {
+	worst := strings.Join(r.WorstMissing, ",")
+	if worst == "" {
+		worst = "none"
+	}
+	fmt.Fprintf(w, "runs:                %s\n", num(r.Runs))
+	fmt.Fprintf(w, "unavailability rate: %s%%\n", fnum(100*r.Rate, 1))
+	fmt.Fprintf(w, "baseline value:      %s\n", num(r.BaselineValue))
+	fmt.Fprintf(w, "mean loss:           %s\n", fnum(r.MeanLoss, 2))
+	fmt.Fprintf(w, "worst loss:          %s (unavailable: %s)\n", num(r.WorstLoss), worst)
+}
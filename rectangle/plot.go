@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// plotRefresh is how often the live plot is redrawn
+const plotRefresh = 10 * time.Millisecond
+
+// terminalPlot draws a live best-value-per-iteration scatter plot with ANSI escapes
+type terminalPlot struct {
+	out    io.Writer
+	width  int
+	height int
+
+	mu    sync.Mutex
+	best  []int
+	drawn bool
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// isTerminal reports whether f is attached to a character device
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// newTerminalPlot starts a background goroutine redrawing the plot until Stop
+func newTerminalPlot(out io.Writer) *terminalPlot {
+	p := &terminalPlot{out: out, width: 60, height: 12, stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(plotRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.render()
+			case <-p.stop:
+				p.render()
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// Record stores the best value after an iteration; it matches the OnProgress signature
+func (p *terminalPlot) Record(iter, best int) {
+	p.mu.Lock()
+	p.best = append(p.best, best)
+	p.mu.Unlock()
+}
+
+// Stop draws the final frame and leaves it on screen
+func (p *terminalPlot) Stop() {
+	close(p.stop)
+	<-p.done
+}
+
+func (p *terminalPlot) render() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.best) == 0 {
+		return
+	}
+
+	lo, hi := p.best[0], p.best[0]
+	for _, v := range p.best {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	grid := make([][]byte, p.height)
+	for r := range grid {
+		grid[r] = []byte(strings.Repeat(" ", p.width))
+	}
+	for c := 0; c < p.width; c++ {
+		idx := (c + 1) * len(p.best) / p.width
+		if idx == 0 {
+			continue
+		}
+		v := p.best[idx-1]
+		r := 0
+		if hi > lo {
+			r = (v - lo) * (p.height - 1) / (hi - lo)
+		}
+		grid[p.height-1-r][c] = '*'
+	}
+
+	if p.drawn {
+		fmt.Fprintf(p.out, "\x1b[%dA", p.height+1)
+	}
+	for r, row := range grid {
+		label := "      "
+		switch r {
+		case 0:
+			label = fmt.Sprintf("%6d", hi)
+		case p.height - 1:
+			label = fmt.Sprintf("%6d", lo)
+		}
+		fmt.Fprintf(p.out, "\x1b[2K%s |%s\n", label, row)
+	}
+	fmt.Fprintf(p.out, "\x1b[2K       +%s iter %d\n", strings.Repeat("-", p.width), len(p.best))
+	p.drawn = true
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// errPolicyRejectsAll is reported when -policy accepts no feasible selection
+var errPolicyRejectsAll = errors.New("policy: no feasible selection satisfies the policy")
+
+// Policy is a Rego policy in package rectangle that vets each result. Its
+// input is the result as -format json writes it, plus the catalog under
+// input.packages as {id, mass, value} objects. A policy rejects a result
+// by adding messages to its deny set, and may replace the selection by
+// defining selected as an array of package identifiers.
+type Policy struct {
+	query rego.PreparedEvalQuery
+}
+
+// PolicyDecision is what a policy says about one result
+type PolicyDecision struct {
+	Deny     []string // violation messages; empty when the result is accepted
+	Selected []string // the policy's replacement selection, nil to keep the result
+}
+
+// loadPolicy compiles the Rego policy at path
+func loadPolicy(path string) (*Policy, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	query, err := rego.New(rego.Query("data.rectangle"), rego.Module(path, string(src))).PrepareForEval(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+	return &Policy{query: query}, nil
+}
+
+// policyPackage is how a catalog package appears in the policy input
+type policyPackage struct {
+	ID    string `json:"id"`
+	Mass  int    `json:"mass"`
+	Value int    `json:"value"`
+}
+
+// Decide evaluates the policy against result
+func (p *Policy) Decide(result OptimizationResult, pkgs []PackageMetadata) (PolicyDecision, error) {
+	var input map[string]any
+	data, err := json.Marshal(result)
+	if err != nil {
+		return PolicyDecision{}, err
+	}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return PolicyDecision{}, err
+	}
+	catalog := make([]policyPackage, len(pkgs))
+	for i, pkg := range pkgs {
+		catalog[i] = policyPackage{ID: pkg.Identifier, Mass: pkg.MassConstraint, Value: pkg.Valuation}
+	}
+	input["packages"] = catalog
+
+	rs, err := p.query.Eval(context.Background(), rego.EvalInput(input))
+	if err != nil {
+		return PolicyDecision{}, fmt.Errorf("policy: %w", err)
+	}
+	var d PolicyDecision
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return d, nil
+	}
+	doc, _ := rs[0].Expressions[0].Value.(map[string]any)
+	if deny, ok := doc["deny"]; ok {
+		msgs, ok := deny.([]any)
+		if !ok {
+			return d, fmt.Errorf("policy: deny must be a set of messages, not %T", deny)
+		}
+		for _, m := range msgs {
+			d.Deny = append(d.Deny, fmt.Sprint(m))
+		}
+		sort.Strings(d.Deny)
+	}
+	if selected, ok := doc["selected"]; ok {
+		ids, ok := selected.([]any)
+		if !ok {
+			return d, fmt.Errorf("policy: selected must be an array of package identifiers, not %T", selected)
+		}
+		d.Selected = make([]string, len(ids))
+		for i, id := range ids {
+			if d.Selected[i], ok = id.(string); !ok {
+				return d, fmt.Errorf("policy: selected contains %v, not a package identifier", id)
+			}
+		}
+	}
+	return d, nil
+}
+
+// policyResult builds the result a policy sees for a selection
+type policyResult func(selected []PackageMetadata) OptimizationResult
+
+// applyPolicy runs the policy on selected: a replacement selection from the
+// policy takes its place, and a rejected selection gives way to the most
+// valuable feasible selection the policy accepts, with the violations
+// reported on stderr
+func applyPolicy(p *Policy, pkgs, selected []PackageMetadata, capacity int, toResult policyResult) ([]PackageMetadata, error) {
+	d, err := p.Decide(toResult(selected), pkgs)
+	if err != nil {
+		return nil, err
+	}
+	if d.Selected != nil {
+		if selected, err = policySelection(pkgs, d.Selected, capacity); err != nil {
+			return nil, err
+		}
+		infof("policy replaced the selection with %s", formatSelection(selected))
+		if d, err = p.Decide(toResult(selected), pkgs); err != nil {
+			return nil, err
+		}
+	}
+	if len(d.Deny) == 0 {
+		return selected, nil
+	}
+	for _, msg := range d.Deny {
+		errorf("policy violation: %s", msg)
+	}
+
+	next, err := bestFirst(pkgs, capacity, func(sel []PackageMetadata) (bool, error) {
+		// a replacement the policy would make is ignored here: it was
+		// already tried above, and the search is for the policy's own
+		// verdict on each selection
+		d, err := p.Decide(toResult(sel), pkgs)
+		return len(d.Deny) == 0, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		return nil, errPolicyRejectsAll
+	}
+	infof("policy: next best feasible selection %s (value %s)", formatSelection(next), num(totalValue(next)))
+	return next, nil
+}
+
+// policySelection resolves the identifiers a policy selected against the catalog
+func policySelection(pkgs []PackageMetadata, ids []string, capacity int) ([]PackageMetadata, error) {
+	byID := make(map[string]PackageMetadata, len(pkgs))
+	for _, pkg := range pkgs {
+		byID[pkg.Identifier] = pkg
+	}
+	var selected []PackageMetadata
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		pkg, ok := byID[id]
+		if !ok || seen[id] {
+			return nil, fmt.Errorf("policy: selected package %q is not in the catalog or listed twice", id)
+		}
+		seen[id] = true
+		selected = append(selected, pkg)
+	}
+	if mass := totalMass(selected); mass > capacity {
+		return nil, fmt.Errorf("policy: selected packages weigh %d, over the capacity %d", mass, capacity)
+	}
+	return selected, nil
+}
+
+// bestFirst offers accept the feasible selections, most valuable first, and
+// returns the first it accepts, or nil when it accepts none. Selections are
+// enumerated in bands below the optimum that double in width, so only as
+// many are built as the search needs.
+func bestFirst(pkgs []PackageMetadata, capacity int, accept func([]PackageMetadata) (bool, error)) ([]PackageMetadata, error) {
+	dp := buildTable(pkgs, capacity)
+	optimum := dp[len(pkgs)][capacity]
+	seen := make(map[string]bool)
+	for tolerance := 0; ; tolerance = max(1, 2*tolerance) {
+		threshold := optimum - tolerance
+		var band [][]PackageMetadata
+		searchAtLeast(dp, pkgs, capacity, &threshold, func(chosen []PackageMetadata) bool {
+			if key := strings.Join(identifiers(chosen), ","); !seen[key] {
+				seen[key] = true
+				band = append(band, append([]PackageMetadata(nil), chosen...))
+			}
+			return len(band) < maxNearOptimal
+		})
+		if len(band) == 0 && threshold <= 0 {
+			return nil, nil
+		}
+		sort.SliceStable(band, func(a, b int) bool { return totalValue(band[a]) > totalValue(band[b]) })
+		for _, sel := range band {
+			if ok, err := accept(sel); err != nil || ok {
+				return sel, err
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writePolicy writes a Rego module to a temporary file and loads it
+func writePolicy(t *testing.T, src string) *Policy {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.rego")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := loadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestApplyPolicy(t *testing.T) {
+	pkgs := NewEmailBasedPackageGenerator().Generate("test@example.com")
+	ctx := HeuristicContext{MaxLoad: defaultMaxLoad, PriorityFactor: 1.0}
+	optimum := (&PriorityBasedOptimizer{}).Optimize(pkgs, ctx) // A,B,D,F
+	toResult := func(sel []PackageMetadata) OptimizationResult { return newResult("test@example.com", "dp", ctx, sel) }
+
+	tests := []struct {
+		name   string
+		policy string
+		want   []string
+		err    bool
+	}{
+		{"accept", "package rectangle\n", []string{"A", "B", "D", "F"}, false},
+		{"next best", `package rectangle
+deny contains "A and B together" if {
+	"A" in input.selected
+	"B" in input.selected
+}`, []string{"A", "D", "E"}, false},
+		{"catalog in input", `package rectangle
+deny contains "over 40" if input.total_mass > 40
+deny contains "unknown package" if {
+	some id in input.selected
+	not id in {p.id | some p in input.packages}
+}`, []string{"B", "D", "F"}, false},
+		{"replace", "package rectangle\nselected := [\"C\", \"D\"]\n", []string{"C", "D"}, false},
+		{"replace then deny", "package rectangle\nselected := [\"C\"]\ndeny contains \"no C\" if \"C\" in input.selected\n", []string{"A", "B", "D", "F"}, false},
+		{"replace overweight", "package rectangle\nselected := [\"B\", \"C\", \"D\"]\n", nil, true},
+		{"replace unknown", "package rectangle\nselected := [\"Q\"]\n", nil, true},
+		{"reject all", "package rectangle\ndeny contains \"never\" if true\n", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := applyPolicy(writePolicy(t, tt.policy), pkgs, optimum, ctx.MaxLoad, toResult)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: err = %v, want error %t", tt.name, err, tt.err)
+			continue
+		}
+		if ids := newResult("", "", ctx, got).Selected; !tt.err && !slices.Equal(ids, tt.want) {
+			t.Errorf("%s: selected %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+}
+
+func TestPolicyRejectsAll(t *testing.T) {
+	pkgs := NewEmailBasedPackageGenerator().Generate("test@example.com")
+	p := writePolicy(t, "package rectangle\ndeny contains \"never\" if true\n")
+	_, err := applyPolicy(p, pkgs, nil, defaultMaxLoad, func(sel []PackageMetadata) OptimizationResult {
+		return newResult("", "dp", HeuristicContext{MaxLoad: defaultMaxLoad}, sel)
+	})
+	if !errors.Is(err, errPolicyRejectsAll) {
+		t.Errorf("err = %v, want errPolicyRejectsAll", err)
+	}
+}
+
+func TestBestFirstOrder(t *testing.T) {
+	pkgs := NewEmailBasedPackageGenerator().Generate("a@b.c")
+	var values []int
+	best, err := bestFirst(pkgs, defaultMaxLoad, func(sel []PackageMetadata) (bool, error) {
+		values = append(values, totalValue(sel))
+		return false, nil
+	})
+	if best != nil || err != nil {
+		t.Fatalf("bestFirst = %v, %v after rejecting everything", best, err)
+	}
+	if values[0] != optimalValue(pkgs, HeuristicContext{MaxLoad: defaultMaxLoad}) || !slices.IsSortedFunc(values, func(a, b int) int { return b - a }) {
+		t.Errorf("selections offered in value order %v, want best first", values)
+	}
+	if last := values[len(values)-1]; last != 0 {
+		t.Errorf("the empty selection was not offered last (last value %d)", last)
+	}
+}
+
+func TestPolicyConflicts(t *testing.T) {
+	for _, args := range [][]string{
+		{"-policy", "p.rego", "-mass-parity", "odd"},
+		{"-policy", "p.rego", "-force-include", "C"},
+		{"-policy", "p.rego", "-package-value-cap", "50"},
+		{"-policy", "p.rego", "-batch", "emails.txt"},
+	} {
+		if _, err := parseOptions(append(args, "test@example.com")); err == nil {
+			t.Errorf("%v accepted", args)
+		}
+	}
+}
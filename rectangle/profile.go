@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"runtime/trace"
+	"time"
+)
+
+// generateProfile holds per-call costs of n repeated calls
+type generateProfile struct {
+	calls  int
+	total  time.Duration
+	allocs uint64
+	bytes  uint64
+}
+
+// measure runs fn n times inside a runtime/trace region named name and
+// records the wall time and heap allocations it made
+func measure(name string, n int, fn func()) generateProfile {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	trace.WithRegion(context.Background(), name, func() {
+		for i := 0; i < n; i++ {
+			fn()
+		}
+	})
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return generateProfile{
+		calls:  n,
+		total:  elapsed,
+		allocs: after.Mallocs - before.Mallocs,
+		bytes:  after.TotalAlloc - before.TotalAlloc,
+	}
+}
+
+// print writes the per-call statistics under label
+func (p generateProfile) print(w io.Writer, label string) {
+	n := max(p.calls, 1)
+	fmt.Fprintf(w, "%-9s %s calls in %s: %s/call, %s allocs/call, %s B/call\n", label,
+		num(p.calls), p.total.Round(time.Microsecond), (p.total / time.Duration(n)).Round(time.Nanosecond),
+		fnum(float64(p.allocs)/float64(n), 1), fnum(float64(p.bytes)/float64(n), 1))
+}
+
+// profileGenerate times n calls of Generate for email next to n DP optimizations
+// of the result, showing how much of a request the generation step costs
+func profileGenerate(w io.Writer, opts *options, n int) {
+	generator := NewEmailBasedPackageGenerator()
+	generator.DomainWeight = opts.domainWeight
+	gen := measure("generate", n, func() { generator.Generate(opts.email) })
+
+	pkgs := generator.Generate(opts.email)
+	dp := &PriorityBasedOptimizer{}
+	ctx := HeuristicContext{MaxLoad: opts.capacity, PriorityFactor: 1.0}
+	opt := measure("optimize", n, func() { dp.Optimize(pkgs, ctx) })
+
+	gen.print(w, "generate")
+	opt.print(w, "optimize")
+	if total := gen.total + opt.total; total > 0 {
+		fmt.Fprintf(w, "generation share: %s%%\n", fnum(100*float64(gen.total)/float64(total), 1))
+	}
+}
+
+// warmUp optimizes n random emails' catalogs with a fresh optimizer before
+// the real run. Go compiles ahead of time, so there is no JIT to warm; what
+// this pre-pays is heap growth, page faults and cold CPU caches on the
+// optimizer's code and table sizes.
+func warmUp(newOptimizer func() LoadOptimizer, n int, ctx HeuristicContext, rng *rand.Rand) time.Duration {
+	generator := NewEmailBasedPackageGenerator()
+	optimizer := newOptimizer()
+	start := time.Now()
+	trace.WithRegion(context.Background(), "warmup", func() {
+		for i := 0; i < n; i++ {
+			optimizer.Optimize(generator.Generate(randomEmail(rng)), ctx)
+		}
+	})
+	return time.Since(start)
+}
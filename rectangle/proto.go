@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MarshalProto encodes a result in the protobuf binary wire format for:
+//
+//	syntax = "proto3";
+//
+//	message OptimizationStats {
+//	  int64  dp_cells_computed       = 1;
+//	  int64  backtrack_steps         = 2;
+//	  int64  total_weight            = 3;
+//	  double value_to_capacity_ratio = 4;
+//	  double lp_bound                = 5;
+//	  double optimality_gap          = 6;
+//	}
+//
+//	message OptimizationResult {
+//	  string            email            = 1;
+//	  string            algorithm        = 2;
+//	  int64             capacity         = 3;
+//	  repeated string   selected         = 4;
+//	  int64             total_mass       = 5;
+//	  int64             total_value      = 6;
+//	  OptimizationStats stats            = 7;
+//	  bool              approximate      = 8;
+//	  int64             optimal_count    = 9;
+//	  string            package_set_hash = 10;
+//	}
+//
+// As in proto3, zero-valued scalars are omitted.
+func MarshalProto(r OptimizationResult) []byte {
+	var b []byte
+	b = appendProtoString(b, 1, r.Email)
+	b = appendProtoString(b, 2, r.Algorithm)
+	b = appendProtoInt(b, 3, int64(r.Capacity))
+	for _, id := range r.Selected {
+		// repeated fields keep empty elements
+		b = appendProtoBytes(b, 4, []byte(id))
+	}
+	b = appendProtoInt(b, 5, int64(r.TotalMass))
+	b = appendProtoInt(b, 6, int64(r.TotalValue))
+	if s := r.Stats; s != nil {
+		var sb []byte
+		sb = appendProtoInt(sb, 1, int64(s.DPCellsComputed))
+		sb = appendProtoInt(sb, 2, int64(s.BacktrackSteps))
+		sb = appendProtoInt(sb, 3, int64(s.TotalWeight))
+		sb = appendProtoDouble(sb, 4, s.ValueToCapacityRatio)
+		sb = appendProtoDouble(sb, 5, s.LPBound)
+		sb = appendProtoDouble(sb, 6, s.OptimalityGap)
+		b = appendProtoBytes(b, 7, sb)
+	}
+	if r.Approximate {
+		b = appendProtoInt(b, 8, 1)
+	}
+	b = appendProtoInt(b, 9, r.OptimalCount)
+	b = appendProtoString(b, 10, r.PackageSetHash)
+	return b
+}
+
+// protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+func appendProtoTag(b []byte, field, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	b = appendProtoTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendProtoString(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	return appendProtoBytes(b, field, []byte(v))
+}
+
+// appendProtoInt encodes an int64 field; negatives take ten bytes as in protobuf
+func appendProtoInt(b []byte, field int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.AppendUvarint(appendProtoTag(b, field, wireVarint), uint64(v))
+}
+
+func appendProtoDouble(b []byte, field int, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.LittleEndian.AppendUint64(appendProtoTag(b, field, wireFixed64), math.Float64bits(v))
+}
+
+// ProtoFormatter prints the binary protobuf encoding, optionally base64 for text channels
+type ProtoFormatter struct {
+	Base64 bool
+}
+
+func (f ProtoFormatter) Format(result OptimizationResult) string {
+	b := MarshalProto(result)
+	if f.Base64 {
+		return base64.StdEncoding.EncodeToString(b) + "\n"
+	}
+	return string(b)
+}
+
+// ProtoTextFormatter prints the protobuf text format of the same message
+type ProtoTextFormatter struct{}
+
+func (ProtoTextFormatter) Format(r OptimizationResult) string {
+	var b strings.Builder
+	field := func(indent, name, value string) {
+		fmt.Fprintf(&b, "%s%s: %s\n", indent, name, value)
+	}
+	if r.Email != "" {
+		field("", "email", strconv.Quote(r.Email))
+	}
+	if r.Algorithm != "" {
+		field("", "algorithm", strconv.Quote(r.Algorithm))
+	}
+	if r.Capacity != 0 {
+		field("", "capacity", strconv.Itoa(r.Capacity))
+	}
+	for _, id := range r.Selected {
+		field("", "selected", strconv.Quote(id))
+	}
+	if r.TotalMass != 0 {
+		field("", "total_mass", strconv.Itoa(r.TotalMass))
+	}
+	if r.TotalValue != 0 {
+		field("", "total_value", strconv.Itoa(r.TotalValue))
+	}
+	if s := r.Stats; s != nil {
+		b.WriteString("stats {\n")
+		for _, f := range []struct {
+			name  string
+			value float64
+			isInt bool
+		}{
+			{"dp_cells_computed", float64(s.DPCellsComputed), true},
+			{"backtrack_steps", float64(s.BacktrackSteps), true},
+			{"total_weight", float64(s.TotalWeight), true},
+			{"value_to_capacity_ratio", s.ValueToCapacityRatio, false},
+			{"lp_bound", s.LPBound, false},
+			{"optimality_gap", s.OptimalityGap, false},
+		} {
+			if f.value == 0 {
+				continue
+			}
+			if f.isInt {
+				field("  ", f.name, strconv.FormatInt(int64(f.value), 10))
+			} else {
+				field("  ", f.name, strconv.FormatFloat(f.value, 'g', -1, 64))
+			}
+		}
+		b.WriteString("}\n")
+	}
+	if r.Approximate {
+		field("", "approximate", "true")
+	}
+	if r.OptimalCount != 0 {
+		field("", "optimal_count", strconv.FormatInt(r.OptimalCount, 10))
+	}
+	if r.PackageSetHash != "" {
+		field("", "package_set_hash", strconv.Quote(r.PackageSetHash))
+	}
+	return b.String()
+}
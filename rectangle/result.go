@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// OptimizationResult is the machine-readable outcome of a single run
+type OptimizationResult struct {
+	Email       string   `json:"email"`
+	Algorithm   string   `json:"algorithm"`
+	Capacity    int      `json:"capacity"`
+	Selected    []string `json:"selected"`
+	TotalMass   int      `json:"total_mass"`
+	TotalValue  int      `json:"total_value"`
+	Approximate bool     `json:"approximate,omitempty"` // greedy fallback after -timeout expired
+
+	OptimalCount   int64  `json:"optimal_count,omitempty"`    // distinct optimal subsets, with -count-optimal-solutions
+	PackageSetHash string `json:"package_set_hash,omitempty"` // short order-independent hash of the catalog
+
+	Stats *OptimizationStats `json:"stats,omitempty"`
+}
+
+// newResult builds the result for a selection, with identifiers in alphabetical order
+func newResult(email, algo string, ctx HeuristicContext, selected []PackageMetadata) OptimizationResult {
+	assertWithinCapacity(selected, ctx)
+	ids := identifiers(selected)
+	sort.Strings(ids)
+	return OptimizationResult{
+		Email:      email,
+		Algorithm:  algo,
+		Capacity:   ctx.MaxLoad,
+		Selected:   ids,
+		TotalMass:  totalMass(selected),
+		TotalValue: totalValue(selected),
+	}
+}
+
+// identifiers returns the package identifiers in selection order
+func identifiers(pkgs []PackageMetadata) []string {
+	ids := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		ids[i] = pkg.Identifier
+	}
+	return ids
+}
+
+// assertWithinCapacity panics if a selection overloads the truck. No optimizer
+// may ever return such a selection, so a violation is a bug (e.g. in
+// backtracking) rather than a user error.
+func assertWithinCapacity(selected []PackageMetadata, ctx HeuristicContext) {
+	if mass := totalMass(selected); mass > ctx.MaxLoad {
+		panic(fmt.Sprintf("internal error: selected mass %d exceeds capacity %d", mass, ctx.MaxLoad))
+	}
+}
+
+// writeJSONFile writes the result as JSON to path, reporting close errors too
+func writeJSONFile(path string, result OptimizationResult, compact bool) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, JSONFormatter{Compact: compact}.Format(result)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
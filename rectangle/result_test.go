@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// brokenBacktrack is backtrackTable with a deliberate bug: it never
+// subtracts the taken item's mass, so every later row is read at full capacity
+func brokenBacktrack(dp DPTable, pkgs []PackageMetadata, W int) []PackageMetadata {
+	res := []PackageMetadata{}
+	for i := len(pkgs); i > 0; i-- {
+		if dp.Get(i, W) != dp.Get(i-1, W) || pkgs[i-1].MassConstraint <= W && dp.Get(i, W) == dp.Get(i-1, W-pkgs[i-1].MassConstraint)+pkgs[i-1].Valuation {
+			res = append(res, pkgs[i-1])
+		}
+	}
+	return res
+}
+
+func TestAssertWithinCapacityCatchesBrokenBacktrack(t *testing.T) {
+	pkgs := NewEmailBasedPackageGenerator().Generate("test@example.com")
+	ctx := HeuristicContext{MaxLoad: defaultMaxLoad}
+	dp := newDenseTable(len(pkgs)+1, ctx.MaxLoad+1)
+	fillTable(dp, pkgs, ctx.MaxLoad)
+
+	newResult("test@example.com", "dp", ctx, backtrackTable(dp, pkgs, ctx.MaxLoad)) // must not panic
+
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "exceeds capacity") {
+			t.Errorf("recovered %q, want an internal capacity error", msg)
+		}
+	}()
+	selected := brokenBacktrack(dp, pkgs, ctx.MaxLoad)
+	newResult("test@example.com", "dp", ctx, selected)
+	t.Errorf("broken backtrack selected mass %d of %d without a panic", totalMass(selected), ctx.MaxLoad)
+}
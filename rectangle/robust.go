@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// maxRobustPackages bounds the exhaustive subset search of RobustSelection
+const maxRobustPackages = 24
+
+// RobustSelection finds the single set of identifiers that performs best
+// across several catalogs (one per candidate email). A set must fit the
+// capacity in every catalog; identifiers missing from a catalog contribute
+// nothing there. With worstCase the score is the minimum value over the
+// catalogs (max-min), otherwise the average. The mass constraints differ per
+// catalog, so no single DP applies; the search is exhaustive, O(2^n·n·c)
+// for n distinct identifiers and c catalogs.
+func RobustSelection(catalogs [][]PackageMetadata, maxLoad int, worstCase bool) (ids []string, score float64, err error) {
+	index := make(map[string]int)
+	for _, pkgs := range catalogs {
+		for _, p := range pkgs {
+			if _, ok := index[p.Identifier]; !ok {
+				index[p.Identifier] = len(ids)
+				ids = append(ids, p.Identifier)
+			}
+		}
+	}
+	if len(ids) > maxRobustPackages {
+		return nil, 0, fmt.Errorf("robust search over %d identifiers exceeds the limit of %d", len(ids), maxRobustPackages)
+	}
+
+	// mass[c][i], value[c][i] for identifier i in catalog c
+	mass := make([][]int, len(catalogs))
+	value := make([][]int, len(catalogs))
+	for c, pkgs := range catalogs {
+		mass[c] = make([]int, len(ids))
+		value[c] = make([]int, len(ids))
+		for _, p := range pkgs {
+			mass[c][index[p.Identifier]] = p.MassConstraint
+			value[c][index[p.Identifier]] = p.Valuation
+		}
+	}
+
+	best, bestScore := uint32(0), -1.0
+	for set := uint32(0); set < 1<<len(ids); set++ {
+		s, feasible := 0.0, true
+		for c := range catalogs {
+			m, v := 0, 0
+			for i := range ids {
+				if set&(1<<i) != 0 {
+					m += mass[c][i]
+					v += value[c][i]
+				}
+			}
+			if m > maxLoad {
+				feasible = false
+				break
+			}
+			switch {
+			case !worstCase:
+				s += float64(v) / float64(len(catalogs))
+			case c == 0 || float64(v) < s:
+				s = float64(v)
+			}
+		}
+		if feasible && s > bestScore {
+			best, bestScore = set, s
+		}
+	}
+
+	var selected []string
+	for i, id := range ids {
+		if best&(1<<i) != 0 {
+			selected = append(selected, id)
+		}
+	}
+	sort.Strings(selected)
+	return selected, bestScore, nil
+}
+
+// runRobust generates a catalog per candidate email and prints the robust selection
+func runRobust(opts *options) int {
+	generator := NewEmailBasedPackageGenerator()
+	generator.DomainWeight = opts.domainWeight
+	catalogs := make([][]PackageMetadata, len(opts.candidates))
+	for i, email := range opts.candidates {
+		catalogs[i] = generator.Generate(email)
+	}
+	worstCase := opts.robustObjective == "worst"
+	ids, score, err := RobustSelection(catalogs, opts.capacity, worstCase)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	printRobust(diagOut, opts.candidates, catalogs, ids, opts.capacity)
+	fmt.Printf("%s\n", strings.Join(ids, ","))
+	infof("%s value: %s", opts.robustObjective, fnum(score, 2))
+	return 0
+}
+
+// printRobust reports the value the robust selection achieves in each catalog
+func printRobust(w io.Writer, emails []string, catalogs [][]PackageMetadata, ids []string, maxLoad int) {
+	chosen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		chosen[id] = true
+	}
+	for i, pkgs := range catalogs {
+		v := 0
+		for _, p := range pkgs {
+			if chosen[p.Identifier] {
+				v += p.Valuation
+			}
+		}
+		fmt.Fprintf(w, "%s: value %s (optimum %s)\n", emails[i], num(v), num(optimalValue(pkgs, HeuristicContext{MaxLoad: maxLoad})))
+	}
+}
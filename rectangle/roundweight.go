@@ -0,0 +1,29 @@
+package main
+
+// RoundWeightPreferenceOptimizer is the DP with a tie-break toward packages
+// whose mass is a multiple of Divisor. Values are scaled by n+1 and each
+// round package gets +1: the bonuses of any selection sum to at most n, less
+// than one unit of real value, so they only decide between equal-value sets.
+type RoundWeightPreferenceOptimizer struct {
+	Divisor int
+}
+
+// Optimize returns an optimal selection, preferring round masses among ties
+func (o *RoundWeightPreferenceOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	scale := len(pkgs) + 1
+	scaled := make([]PackageMetadata, len(pkgs))
+	byID := make(map[string]PackageMetadata, len(pkgs))
+	for i, p := range pkgs {
+		byID[p.Identifier] = p
+		scaled[i] = p
+		scaled[i].Valuation = p.Valuation * scale
+		if o.Divisor > 0 && p.MassConstraint%o.Divisor == 0 {
+			scaled[i].Valuation++
+		}
+	}
+	selected := (&PriorityBasedOptimizer{}).Optimize(scaled, ctx)
+	for i, p := range selected {
+		selected[i] = byID[p.Identifier]
+	}
+	return selected
+}
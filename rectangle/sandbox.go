@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// sandboxRequest is what the parent sends the sandboxed child on stdin
+type sandboxRequest struct {
+	Algorithm string               `json:"algorithm"`
+	Solver    sandboxSolverOptions `json:"solver"`
+	Context   HeuristicContext     `json:"context"`
+	Packages  []PackageMetadata    `json:"packages"`
+}
+
+// sandboxSolverOptions are the options the -algo constructors read; the
+// child applies them so it builds the same optimizer the parent would
+type sandboxSolverOptions struct {
+	RandSeed         int64  `json:"rand_seed"`
+	Lookahead        int    `json:"weight_lookahead,omitempty"`
+	DPLayout         string `json:"dp_layout,omitempty"`
+	NoAllocDP        bool   `json:"no_allocation_dp,omitempty"`
+	ReverseBacktrack bool   `json:"reverse_backtrack,omitempty"`
+	CompactDP        bool   `json:"compact_dp,omitempty"`
+	CacheDPTable     string `json:"cache_dp_table,omitempty"`
+}
+
+// solverOptions captures the optimizer settings of opts for the child
+func solverOptions(opts *options) sandboxSolverOptions {
+	return sandboxSolverOptions{
+		RandSeed:         opts.randSeed,
+		Lookahead:        opts.lookahead,
+		DPLayout:         opts.dpLayout,
+		NoAllocDP:        opts.noAllocDP,
+		ReverseBacktrack: opts.reverseBacktrack,
+		CompactDP:        opts.compactDP,
+		CacheDPTable:     opts.cacheDPTable,
+	}
+}
+
+// apply copies the forwarded settings into the child's options
+func (s sandboxSolverOptions) apply(opts *options) {
+	opts.randSeed = s.RandSeed
+	opts.lookahead = s.Lookahead
+	opts.dpLayout = s.DPLayout
+	opts.noAllocDP = s.NoAllocDP
+	opts.reverseBacktrack = s.ReverseBacktrack
+	opts.compactDP = s.CompactDP
+	opts.cacheDPTable = s.CacheDPTable
+}
+
+// sandboxResponse is what the child writes back on stdout
+type sandboxResponse struct {
+	Selected []string `json:"selected"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// SandboxOptimizer runs the Algorithm optimizer in a child copy of this
+// binary so a panic or OOM there cannot take the parent down. Optimize
+// returns nil on failure and leaves the reason in Err.
+type SandboxOptimizer struct {
+	Algorithm string
+	Solver    sandboxSolverOptions
+	Timeout   time.Duration // 0 waits indefinitely
+	Err       error
+}
+
+// Optimize sends the catalog to the child and maps its answer back to packages
+func (o *SandboxOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	resp, err := runSandboxed(sandboxRequest{Algorithm: o.Algorithm, Solver: o.Solver, Context: ctx, Packages: pkgs}, o.Timeout)
+	if err == nil && resp.Error != "" {
+		err = fmt.Errorf("sandboxed optimizer: %s", resp.Error)
+	}
+	if o.Err = err; err != nil {
+		return nil
+	}
+
+	byID := make(map[string]PackageMetadata, len(pkgs))
+	for _, p := range pkgs {
+		byID[p.Identifier] = p
+	}
+	selected := []PackageMetadata{}
+	for _, id := range resp.Selected {
+		p, ok := byID[id]
+		if !ok {
+			o.Err = fmt.Errorf("sandboxed optimizer selected unknown package %q", id)
+			return nil
+		}
+		selected = append(selected, p)
+	}
+	return selected
+}
+
+// runSandboxed starts the child, feeds it req and waits for its response,
+// killing it once timeout elapses
+func runSandboxed(req sandboxRequest, timeout time.Duration) (sandboxResponse, error) {
+	var resp sandboxResponse
+	exe, err := os.Executable()
+	if err != nil {
+		return resp, err
+	}
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		return resp, err
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		stdinR.Close()
+		stdinW.Close()
+		return resp, err
+	}
+	proc, err := os.StartProcess(exe, []string{exe, "-sandbox-child"}, &os.ProcAttr{
+		Files: []*os.File{stdinR, stdoutW, os.Stderr},
+	})
+	// the child holds its own copies of these ends now
+	stdinR.Close()
+	stdoutW.Close()
+	if err != nil {
+		stdinW.Close()
+		stdoutR.Close()
+		return resp, fmt.Errorf("start sandbox: %w", err)
+	}
+	defer stdoutR.Close()
+
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() { proc.Kill() })
+		defer timer.Stop()
+	}
+
+	go func() {
+		json.NewEncoder(stdinW).Encode(req)
+		stdinW.Close()
+	}()
+	decodeErr := json.NewDecoder(stdoutR).Decode(&resp)
+	state, err := proc.Wait()
+	switch {
+	case err != nil:
+		return resp, fmt.Errorf("sandbox: %w", err)
+	case !state.Success():
+		return resp, fmt.Errorf("sandbox exited abnormally: %s", state)
+	case decodeErr != nil:
+		return resp, fmt.Errorf("sandbox response: %w", decodeErr)
+	}
+	return resp, nil
+}
+
+// runSandboxChild is the child side: decode a request from stdin, optimize,
+// and report the selection (or a recovered panic) on stdout
+func runSandboxChild(opts *options) int {
+	var req sandboxRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, "sandbox request:", err)
+		return 1
+	}
+	resp := sandboxResponse{Selected: []string{}}
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				resp.Error = fmt.Sprint(r)
+			}
+		}()
+		newOptimizer, ok := optimizers[req.Algorithm]
+		if !ok {
+			resp.Error = fmt.Sprintf("unknown optimizer %q", req.Algorithm)
+			return
+		}
+		if _, ok := dpLayouts[req.Solver.DPLayout]; !ok {
+			resp.Error = fmt.Sprintf("unknown DP layout %q", req.Solver.DPLayout)
+			return
+		}
+		req.Solver.apply(opts)
+		for _, p := range newOptimizer(opts).Optimize(req.Packages, req.Context) {
+			resp.Selected = append(resp.Selected, p.Identifier)
+		}
+	}()
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		fmt.Fprintln(os.Stderr, "sandbox response:", err)
+		return 1
+	}
+	return 0
+}
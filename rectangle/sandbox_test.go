@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// TestSandboxSolverOptions checks that the options forwarded to the child
+// make it build an optimizer that selects what the parent's would
+func TestSandboxSolverOptions(t *testing.T) {
+	pkgs := appendSynthetic(NewEmailBasedPackageGenerator().Generate("test@example.com"), 30, 50, 3)
+	ctx := HeuristicContext{MaxLoad: 50, PriorityFactor: 1.0}
+	for _, args := range [][]string{
+		{"-algo", "sa", "-rand-seed", "5"},
+		{"-algo", "sa", "-rand-seed", "9"},
+		{"-algo", "greedy", "-weight-lookahead", "2"},
+		{"-dp-layout", "delta"},
+		{"-reverse-backtrack"},
+		{"-no-allocation-dp"},
+		{"-compact-dp"},
+	} {
+		parent, err := parseOptions(append(args, "test@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(sandboxRequest{Algorithm: parent.algo, Solver: solverOptions(parent)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var req sandboxRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			t.Fatal(err)
+		}
+		child, err := parseOptions([]string{"-sandbox-child"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Solver.apply(child)
+
+		want := identifiers(optimizers[parent.algo](parent).Optimize(pkgs, ctx))
+		if got := identifiers(optimizers[req.Algorithm](child).Optimize(pkgs, ctx)); !slices.Equal(got, want) {
+			t.Errorf("%v: child selected %v, parent %v", args, got, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+// optimizationResultSchema is the JSON Schema (draft-07) for OptimizationResult,
+// served as application/schema+json. Keep it in sync with the struct tags.
+const optimizationResultSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "OptimizationResult",
+  "type": "object",
+  "required": ["email", "algorithm", "capacity", "selected", "total_mass", "total_value"],
+  "properties": {
+    "email": {"type": "string"},
+    "algorithm": {"type": "string"},
+    "capacity": {"type": "integer", "minimum": 0},
+    "selected": {
+      "type": "array",
+      "items": {"type": "string"},
+      "uniqueItems": true
+    },
+    "total_mass": {"type": "integer", "minimum": 0},
+    "total_value": {"type": "integer"},
+    "approximate": {"type": "boolean"},
+    "optimal_count": {"type": "integer", "minimum": 1},
+    "package_set_hash": {"type": "string", "pattern": "^[0-9a-f]{8}$"},
+    "stats": {
+      "type": "object",
+      "required": ["dp_cells_computed", "backtrack_steps", "total_weight", "value_to_capacity_ratio", "lp_bound", "optimality_gap"],
+      "properties": {
+        "dp_cells_computed": {"type": "integer", "minimum": 0},
+        "backtrack_steps": {"type": "integer", "minimum": 0},
+        "total_weight": {"type": "integer", "minimum": 0},
+        "value_to_capacity_ratio": {"type": "number"},
+        "lp_bound": {"type": "number"},
+        "optimality_gap": {"type": "number"}
+      },
+      "additionalProperties": false
+    }
+  },
+  "additionalProperties": false
+}
+`
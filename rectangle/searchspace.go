@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// FeasibleSubsets counts the subsets of pkgs whose total mass is at most
+// maxLoad, the empty one included. It is the DP with counts in place of
+// values: ways[w] is the number of subsets of the items so far weighing
+// exactly w, updated from high w to low so each item is used at most once.
+func FeasibleSubsets(pkgs []PackageMetadata, maxLoad int) *big.Int {
+	ways := make([]*big.Int, maxLoad+1)
+	for w := range ways {
+		ways[w] = new(big.Int)
+	}
+	ways[0].SetInt64(1)
+	for _, p := range pkgs {
+		for w := maxLoad; w >= p.MassConstraint; w-- {
+			ways[w].Add(ways[w], ways[w-p.MassConstraint])
+		}
+	}
+	total := new(big.Int)
+	for _, n := range ways {
+		total.Add(total, n)
+	}
+	return total
+}
+
+// printSearchSpace writes the feasible subset count next to the 2^n subsets
+func printSearchSpace(w io.Writer, pkgs []PackageMetadata, maxLoad int) {
+	feasible := FeasibleSubsets(pkgs, maxLoad)
+	all := new(big.Int).Lsh(big.NewInt(1), uint(len(pkgs)))
+	share, _ := new(big.Rat).SetFrac(feasible, all).Float64()
+	fmt.Fprintf(w, "feasible subsets at capacity %s: %s of %s (%s%%)\n", num(maxLoad), feasible, all, fnum(100*share, 2))
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// selfHashPlaceholder is the expectedBinaryHash a release is first built
+// with; that build's plain SHA-256 is the hash to embed in the second
+const selfHashPlaceholder = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// expectedBinaryHash is the hex SHA-256 of the released binary with this
+// string itself reset to selfHashPlaceholder, so that embedding the hash
+// does not change what it is a hash of. With an empty build ID, -X changes
+// nothing but the string's bytes, and a release takes two builds, with
+// $placeholder set to selfHashPlaceholder:
+//
+//	go build -ldflags "-buildid= -X main.expectedBinaryHash=$placeholder" -o rectangle .
+//	go build -ldflags "-buildid= -X main.expectedBinaryHash=$(sha256sum rectangle | cut -c1-64)" -o rectangle .
+var expectedBinaryHash string
+
+// executableHash returns the hex SHA-256 of the running binary, with
+// expectedBinaryHash reset to the placeholder
+func executableHash() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return selfHash(data, expectedBinaryHash), nil
+}
+
+// selfHash is the hex SHA-256 of a binary with every copy of its embedded
+// hash replaced by selfHashPlaceholder
+func selfHash(binary []byte, embedded string) string {
+	if len(embedded) == len(selfHashPlaceholder) {
+		binary = bytes.ReplaceAll(binary, []byte(embedded), []byte(selfHashPlaceholder))
+	}
+	sum := sha256.Sum256(binary)
+	return hex.EncodeToString(sum[:])
+}
+
+// runSelfCheck health-checks the DP optimizer and compares the binary's hash
+// with the embedded one, returning the exit code
+func runSelfCheck() int {
+	if err := OptimizerHealthCheck(&PriorityBasedOptimizer{}); err != nil {
+		fmt.Fprintln(os.Stderr, "self-check:", err)
+		return 1
+	}
+	actual, err := executableHash()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "self-check:", err)
+		return 1
+	}
+	if expectedBinaryHash == "" || expectedBinaryHash == selfHashPlaceholder {
+		errorf("self-check: no expected hash embedded in this build (sha256 %s)", actual)
+		return 1
+	}
+	if actual != expectedBinaryHash {
+		errorf("self-check: binary hash %s does not match expected %s; the binary may have been tampered with", actual, expectedBinaryHash)
+		return 1
+	}
+	verbosef("self-check: sha256 %s ok", actual)
+	return 0
+}
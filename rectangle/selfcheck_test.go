@@ -0,0 +1,66 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelfHashIgnoresEmbeddedHash(t *testing.T) {
+	build := func(embedded string) []byte {
+		return []byte("header " + embedded + " body " + embedded + " trailer")
+	}
+	placeholder := build(selfHashPlaceholder)
+	sum := sha256.Sum256(placeholder)
+	want := hex.EncodeToString(sum[:])
+	if got := selfHash(build(want), want); got != want {
+		t.Errorf("selfHash of the release build = %s, want the placeholder build's %s", got, want)
+	}
+	if got := selfHash(placeholder, selfHashPlaceholder); got != want {
+		t.Errorf("selfHash of the placeholder build = %s, want its plain SHA-256 %s", got, want)
+	}
+}
+
+// TestSelfCheckRelease builds a release the documented way and checks that
+// -self-check passes on it and fails once a byte is changed
+func TestSelfCheckRelease(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the binary twice")
+	}
+	bin := filepath.Join(t.TempDir(), "rectangle")
+	build := func(hash string) {
+		t.Helper()
+		out, err := exec.Command("go", "build", "-ldflags", "-buildid= -X main.expectedBinaryHash="+hash, "-o", bin, ".").CombinedOutput()
+		if err != nil {
+			t.Fatalf("go build: %v\n%s", err, out)
+		}
+	}
+	build(selfHashPlaceholder)
+	data, err := os.ReadFile(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	build(hex.EncodeToString(sum[:]))
+
+	if out, err := exec.Command(bin, "-self-check").CombinedOutput(); err != nil {
+		t.Fatalf("-self-check on the release: %v\n%s", err, out)
+	}
+
+	data, err = os.ReadFile(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(bin, data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(bin, "-self-check").CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "does not match") {
+		t.Errorf("-self-check on a tampered binary: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// resultHMAC is the hex HMAC-SHA256 under key of the identifiers sorted and
+// joined by commas, so signing does not depend on output order
+func resultHMAC(ids []string, key []byte) string {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join(sorted, ",")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyResultHash checks a signed "A,B,C|hex" line, as printed by
+// -result-hash, against key
+func verifyResultHash(line string, key []byte) error {
+	answer, sig, ok := strings.Cut(strings.TrimSpace(line), "|")
+	if !ok {
+		return errors.New("signed result has no |signature")
+	}
+	var ids []string
+	if answer != "" && answer != "No viable packages" {
+		ids = strings.Split(answer, ",")
+	}
+	want, err := hex.DecodeString(resultHMAC(ids, key))
+	if err != nil {
+		return err
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("signature is not hex: %w", err)
+	}
+	if !hmac.Equal(got, want) {
+		return errors.New("result signature does not match; the result was altered or signed with another key")
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// resultSignals is empty where SIGUSR1 does not exist
+var resultSignals []os.Signal
@@ -0,0 +1,11 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// resultSignals end -timeout early with the best available selection
+var resultSignals = []os.Signal{syscall.SIGUSR1}
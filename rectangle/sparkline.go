@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// sparkTicks are the eight block heights of a sparkline, lowest first
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// historyWidth is the number of time buckets in -package-count-history
+const historyWidth = 40
+
+// Sparkline draws values as width block characters scaled to the maximum.
+// Longer series are summed into width buckets; shorter ones are drawn as is.
+func Sparkline(values []int, width int) string {
+	if width > 0 && len(values) > width {
+		buckets := make([]int, width)
+		for i, v := range values {
+			buckets[i*width/len(values)] += v
+		}
+		values = buckets
+	}
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case v <= 0:
+			b.WriteRune(' ')
+		default:
+			b.WriteRune(sparkTicks[(v*len(sparkTicks)-1)/peak])
+		}
+	}
+	return b.String()
+}
+
+// selectionHistory counts, per identifier, how often it was selected in each
+// of width equal time buckets spanning the stored results
+func selectionHistory(records []storedResult, width int) map[string][]int {
+	history := make(map[string][]int)
+	if len(records) == 0 {
+		return history
+	}
+	first, last := records[0].Time, records[0].Time
+	for _, r := range records {
+		if r.Time.Before(first) {
+			first = r.Time
+		}
+		if r.Time.After(last) {
+			last = r.Time
+		}
+	}
+	span := last.Sub(first)
+	for _, r := range records {
+		bucket := 0
+		if span > 0 {
+			bucket = min(width-1, int(int64(width)*int64(r.Time.Sub(first))/int64(span)))
+		}
+		for _, id := range r.Result.Selected {
+			if history[id] == nil {
+				history[id] = make([]int, width)
+			}
+			history[id][bucket]++
+		}
+	}
+	return history
+}
+
+// printPackageCountHistory writes one sparkline per identifier, most selected first
+func printPackageCountHistory(w io.Writer, records []storedResult) {
+	if len(records) == 0 {
+		fmt.Fprintln(w, "no stored results")
+		return
+	}
+	history := selectionHistory(records, historyWidth)
+	totals := make(map[string]int, len(history))
+	ids := make([]string, 0, len(history))
+	for id, counts := range history {
+		for _, c := range counts {
+			totals[id] += c
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if totals[ids[i]] != totals[ids[j]] {
+			return totals[ids[i]] > totals[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	fmt.Fprintf(w, "%d results from %s to %s\n", len(records),
+		records[0].Time.Format("2006-01-02 15:04"), records[len(records)-1].Time.Format("2006-01-02 15:04"))
+	for _, id := range ids {
+		fmt.Fprintf(w, "%-4s %5d |%s|\n", id, totals[id], Sparkline(history[id], historyWidth))
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+// OptimizationStats summarizes the work done and the quality of a result
+type OptimizationStats struct {
+	DPCellsComputed      int     `json:"dp_cells_computed"`
+	BacktrackSteps       int     `json:"backtrack_steps"`
+	TotalWeight          int     `json:"total_weight"`
+	ValueToCapacityRatio float64 `json:"value_to_capacity_ratio"`
+	LPBound              float64 `json:"lp_bound"`
+	OptimalityGap        float64 `json:"optimality_gap"`
+}
+
+// DPCounters is the work a DP optimizer counted during its last Optimize
+type DPCounters struct {
+	CellsComputed  int // table cells written by the fill
+	BacktrackSteps int // table rows examined while recovering the selection
+}
+
+// dpWork is implemented by the optimizers that count their DP work
+type dpWork interface {
+	Work() DPCounters
+}
+
+// computeStats derives OptimizationStats for a selection over pkgs; the DP
+// counters are those solver measured, and stay zero when it counts none
+func computeStats(pkgs, selected []PackageMetadata, ctx HeuristicContext, solver LoadOptimizer) *OptimizationStats {
+	value := totalValue(selected)
+	stats := &OptimizationStats{
+		TotalWeight: totalMass(selected),
+		LPBound:     UpperBound(pkgs, ctx.MaxLoad),
+	}
+	if dp, ok := solver.(dpWork); ok {
+		work := dp.Work()
+		stats.DPCellsComputed = work.CellsComputed
+		stats.BacktrackSteps = work.BacktrackSteps
+	}
+	if ctx.MaxLoad > 0 {
+		stats.ValueToCapacityRatio = float64(value) / float64(ctx.MaxLoad)
+	}
+	if stats.LPBound > 0 {
+		stats.OptimalityGap = (stats.LPBound - float64(value)) / stats.LPBound
+	}
+	return stats
+}
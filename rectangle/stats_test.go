@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestDPStatsMeasured(t *testing.T) {
+	stats := func(args ...string) *OptimizationStats {
+		t.Helper()
+		var code int
+		out := captureStdout(t, func() {
+			code = run(append([]string{"-quiet", "-format", "json", "-include-stats"}, append(args, "test@example.com")...))
+		})
+		if code != 0 {
+			t.Fatalf("%v: exit %d", args, code)
+		}
+		var result OptimizationResult
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return result.Stats
+	}
+
+	// 8 packages at capacity 50: the fill writes every cell of rows 1..8 and
+	// the backtrack visits each row once
+	n := len(NewEmailBasedPackageGenerator().Generate("test@example.com"))
+	for _, args := range [][]string{{}, {"-dp-layout", "flat"}, {"-reverse-backtrack"}, {"-no-allocation-dp"}} {
+		s := stats(args...)
+		if s.DPCellsComputed != n*51 || s.BacktrackSteps != n {
+			t.Errorf("%v: %d cells, %d steps, want %d and %d", args, s.DPCellsComputed, s.BacktrackSteps, n*51, n)
+		}
+	}
+
+	if s := stats("-algo", "lazy"); s.DPCellsComputed == 0 || s.DPCellsComputed >= n*51 {
+		t.Errorf("lazy: %d cells, want between 0 and %d", s.DPCellsComputed, n*51)
+	}
+	if s := stats("-algo", "greedy"); s.DPCellsComputed != 0 || s.BacktrackSteps != 0 {
+		t.Errorf("greedy: %d cells, %d steps, want none", s.DPCellsComputed, s.BacktrackSteps)
+	}
+
+	cache := filepath.Join(t.TempDir(), "dp.gob")
+	if s := stats("-cache-dp-table", cache); s.DPCellsComputed != n*51 {
+		t.Errorf("cache miss: %d cells, want %d", s.DPCellsComputed, n*51)
+	}
+	if s := stats("-cache-dp-table", cache); s.DPCellsComputed != 0 || s.BacktrackSteps != n {
+		t.Errorf("cache hit: %d cells, %d steps, want 0 and %d", s.DPCellsComputed, s.BacktrackSteps, n)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// storedResult is one line of the -persist results database (JSON Lines)
+type storedResult struct {
+	Time      time.Time          `json:"time"`
+	EmailHash string             `json:"email_hash"`
+	Result    OptimizationResult `json:"result"`
+}
+
+// emailHash is the hex SHA-256 of the email, the key results are stored under
+func emailHash(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:])
+}
+
+// appendResult adds a result to the database at path, creating it if needed
+func appendResult(path string, result OptimizationResult, at time.Time) error {
+	line, err := json.Marshal(storedResult{Time: at.UTC(), EmailHash: emailHash(result.Email), Result: result})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readResults loads every stored result in insertion order; a missing
+// database is empty rather than an error
+func readResults(path string) ([]storedResult, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []storedResult
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec storedResult
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, sc.Err()
+}
+
+// bestStored returns the highest-valued stored result for the email hash,
+// the earliest one among ties
+func bestStored(records []storedResult, hash string) (storedResult, bool) {
+	var best storedResult
+	found := false
+	for _, rec := range records {
+		if rec.EmailHash == hash && (!found || rec.Result.TotalValue > best.Result.TotalValue) {
+			best, found = rec, true
+		}
+	}
+	return best, found
+}
+
+// persistImprovement appends the result only when it beats every stored
+// result for the same email and reports whether it did
+func persistImprovement(path string, result OptimizationResult, at time.Time) (bool, error) {
+	records, err := readResults(path)
+	if err != nil {
+		return false, err
+	}
+	if best, ok := bestStored(records, emailHash(result.Email)); ok && result.TotalValue <= best.Result.TotalValue {
+		return false, nil
+	}
+	return true, appendResult(path, result, at)
+}
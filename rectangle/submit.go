@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// submission is the payload POSTed to the challenge endpoint
+type submission struct {
+	Email  string `json:"email"`
+	Answer string `json:"answer"`
+}
+
+// newSubmitRequest builds the POST of the result's answer to url, with a
+// bearer token when one is given
+func newSubmitRequest(url string, token []byte, result OptimizationResult) (*http.Request, []byte, error) {
+	body, err := json.Marshal(submission{Email: result.Email, Answer: strings.Join(result.Selected, ",")})
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+string(token))
+	}
+	return req, body, nil
+}
+
+// submitResult POSTs the result's answer to url and returns the response status and body
+func submitResult(client *http.Client, url string, token []byte, result OptimizationResult) (int, string, error) {
+	req, _, err := newSubmitRequest(url, token, result)
+	if err != nil {
+		return 0, "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, "", err
+	}
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, string(respBody), fmt.Errorf("submit %s: %s", result.Email, resp.Status)
+	}
+	return resp.StatusCode, string(respBody), nil
+}
+
+// printSubmitRequest shows the request a submission would send, token redacted
+func printSubmitRequest(w io.Writer, url string, token []byte, result OptimizationResult) error {
+	req, body, err := newSubmitRequest(url, token, result)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s %s\n", req.Method, req.URL)
+	fmt.Fprintf(w, "Content-Type: %s\n", req.Header.Get("Content-Type"))
+	if len(token) > 0 {
+		fmt.Fprintln(w, "Authorization: Bearer <redacted>")
+	}
+	fmt.Fprintf(w, "\n%s\n", body)
+	return nil
+}
+
+// simulateChallenge submits the result with the bearer token, or only shows
+// the request with -dry-run, and prints the response status and body
+func simulateChallenge(opts *options, result OptimizationResult) int {
+	if opts.dryRun {
+		if err := printSubmitRequest(os.Stdout, opts.submitURL, opts.token, result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	status, body, err := submitResult(client, opts.submitURL, opts.token, result)
+	if status != 0 {
+		fmt.Printf("status: %d\n%s\n", status, body)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// tokenBucket limits submissions to a steady rate, refilled by a time.Ticker
+type tokenBucket struct {
+	tokens chan struct{}
+	ticker *time.Ticker
+	done   chan struct{}
+}
+
+// newTokenBucket allows perSecond requests per second with a burst of one
+func newTokenBucket(perSecond float64) *tokenBucket {
+	b := &tokenBucket{
+		tokens: make(chan struct{}, 1),
+		ticker: time.NewTicker(time.Duration(float64(time.Second) / perSecond)),
+		done:   make(chan struct{}),
+	}
+	b.tokens <- struct{}{}
+	go func() {
+		for {
+			select {
+			case <-b.ticker.C:
+				select {
+				case b.tokens <- struct{}{}:
+				default: // bucket full
+				}
+			case <-b.done:
+				return
+			}
+		}
+	}()
+	return b
+}
+
+// Wait blocks until a token is available and returns how long it waited
+func (b *tokenBucket) Wait() time.Duration {
+	select {
+	case <-b.tokens:
+		return 0
+	default:
+	}
+	start := time.Now()
+	<-b.tokens
+	return time.Since(start)
+}
+
+// Stop releases the ticker goroutine
+func (b *tokenBucket) Stop() {
+	b.ticker.Stop()
+	close(b.done)
+}
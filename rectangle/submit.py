@@ -1,3 +1,8 @@
+import re
+import subprocess
+import tempfile
+from pathlib import Path
+
 import requests
 
 BASE = "https://challenge.rectanglehq.com"
@@ -5,9 +10,48 @@ BASE = "https://challenge.rectanglehq.com"
 APP_KEY = "FDE-CHALLENGE-01"
 IDEMPOTENCY_KEY = "9a4ded66eefada956217eab16adaa9724bd2c7d358b31276a5fdbdaff0de8f546e11398b953b2498150949aa51a718b2cd951e1bd33be70e06c563646f026772"
 
-# Load your fixed Go source
-with open(r"decoded_challenge.go", "r", encoding="utf-8") as f:
-    fixed_go_code = f.read()
+def bundle_package():
+    """Merge the package's Go files for this platform into one source file.
+
+    decoded_challenge.go's main() calls run() from cli.go, so no single file
+    of the package builds on its own; the challenge takes one file, so the
+    imports of every file are merged and the bodies concatenated.
+    """
+    files = subprocess.run(
+        ["go", "list", "-f", '{{join .GoFiles "\\n"}}', "."],
+        check=True, capture_output=True, text=True,
+    ).stdout.split()
+    imports, bodies = set(), []
+    for name in files:
+        src = Path(name).read_text(encoding="utf-8")
+        src = re.sub(r"(?m)^//go:build .*\n", "", src)
+        src = re.sub(r"(?m)^package main\n", "", src, count=1)
+        for block in re.findall(r"(?ms)^import \((.*?)^\)\n", src):
+            imports.update(line.strip() for line in block.splitlines() if line.strip())
+        imports.update(re.findall(r'(?m)^import ("[^"]+")\n', src))
+        src = re.sub(r"(?ms)^import \(.*?^\)\n|^import \"[^\"]+\"\n", "", src)
+        bodies.append(f"// ---- {name} ----\n{src.strip()}\n")
+    header = "package main\n\nimport (\n" + "".join(f"\t{i}\n" for i in sorted(imports)) + ")\n\n"
+    return header + "\n".join(bodies)
+
+
+def check_builds(code):
+    """Build the bundle on its own, with the module's dependencies, as the challenge will."""
+    root = Path(subprocess.run(
+        ["go", "env", "GOMOD"], check=True, capture_output=True, text=True,
+    ).stdout.strip()).parent
+    with tempfile.TemporaryDirectory() as d:
+        Path(d, "main.go").write_text(code, encoding="utf-8")
+        Path(d, "go.mod").write_text(
+            re.sub(r"(?m)^module .*$", "module submission", (root / "go.mod").read_text(encoding="utf-8")),
+            encoding="utf-8",
+        )
+        Path(d, "go.sum").write_text((root / "go.sum").read_text(encoding="utf-8"), encoding="utf-8")
+        subprocess.run(["go", "build", "-o", str(Path(d, "submission")), "."], cwd=d, check=True)
+
+
+fixed_go_code = bundle_package()
+check_builds(fixed_go_code)
 
 print(fixed_go_code)
 input("Enter to send")
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SweepPoint is the optimizer result at one capacity
+type SweepPoint struct {
+	Capacity int
+	Selected []PackageMetadata
+}
+
+// parseSweep parses a "from:to" capacity range
+func parseSweep(spec string) (from, to int, err error) {
+	a, b, ok := strings.Cut(spec, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid sweep %q: want from:to", spec)
+	}
+	if from, err = strconv.Atoi(a); err != nil {
+		return 0, 0, fmt.Errorf("invalid sweep start %q", a)
+	}
+	if to, err = strconv.Atoi(b); err != nil {
+		return 0, 0, fmt.Errorf("invalid sweep end %q", b)
+	}
+	if from < 0 || to < from {
+		return 0, 0, fmt.Errorf("invalid sweep range %d:%d", from, to)
+	}
+	return from, to, nil
+}
+
+// SweepCapacities optimizes pkgs at every capacity in [from, to] using a pool
+// of workers, each with its own optimizer and DP tables. Results are in
+// capacity order regardless of completion order.
+func SweepCapacities(pkgs []PackageMetadata, from, to, workers int, newOptimizer func() LoadOptimizer, factor float64) []SweepPoint {
+	points := make([]SweepPoint, to-from+1)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for k := 0; k < max(workers, 1); k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			optimizer := newOptimizer()
+			for idx := range jobs {
+				ctx := HeuristicContext{MaxLoad: from + idx, PriorityFactor: factor}
+				points[idx] = SweepPoint{Capacity: ctx.MaxLoad, Selected: optimizer.Optimize(pkgs, ctx)}
+			}
+		}()
+	}
+	for idx := range points {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+	return points
+}
+
+// printSweep writes one line per capacity
+func printSweep(w io.Writer, points []SweepPoint) {
+	for _, p := range points {
+		ids := make([]string, len(p.Selected))
+		for i, pkg := range p.Selected {
+			ids[i] = pkg.Identifier
+		}
+		sort.Strings(ids)
+		fmt.Fprintf(w, "capacity %s: %s (mass %s, value %s)\n", num(p.Capacity), strings.Join(ids, ","), num(totalMass(p.Selected)), num(totalValue(p.Selected)))
+	}
+}
+
+// CapacityProfile returns the optimum at 10%, 20%, ..., 100% of maxLoad. Row
+// n of a DP table built for maxLoad already holds the optimum for every
+// smaller capacity, so one table serves all ten levels.
+func CapacityProfile(pkgs []PackageMetadata, maxLoad int) []SweepPoint {
+	dp := buildTable(pkgs, maxLoad)
+	points := make([]SweepPoint, 10)
+	for i := range points {
+		capacity := maxLoad * (i + 1) / 10
+		points[i] = SweepPoint{Capacity: capacity, Selected: backtrack(dp, pkgs, capacity)}
+	}
+	return points
+}
+
+// printCapacityProfile writes the loading profile as a table
+func printCapacityProfile(w io.Writer, points []SweepPoint) {
+	fmt.Fprintf(w, "%5s %8s %6s %6s  %s\n", "load", "capacity", "mass", "value", "selected")
+	for i, p := range points {
+		ids := make([]string, len(p.Selected))
+		for j, pkg := range p.Selected {
+			ids[j] = pkg.Identifier
+		}
+		sort.Strings(ids)
+		fmt.Fprintf(w, "%4d%% %8s %6s %6s  %s\n", (i+1)*10, num(p.Capacity), num(totalMass(p.Selected)), num(totalValue(p.Selected)), strings.Join(ids, ","))
+	}
+}
+
+// Breakpoint is a capacity at which the optimum value rises
+type Breakpoint struct {
+	Capacity, Value, Jump int
+	Added, Removed        []string // selection change from the previous breakpoint
+}
+
+// CapacityBreakpoints walks row n of one DP table from capacity 1 to maxLoad
+// and records every capacity where the optimum increases, with the packages
+// the recovered selection gains and drops there
+func CapacityBreakpoints(pkgs []PackageMetadata, maxLoad int) []Breakpoint {
+	dp := buildTable(pkgs, maxLoad)
+	n := len(pkgs)
+	prev := make(map[string]bool)
+	for _, p := range backtrack(dp, pkgs, 0) {
+		prev[p.Identifier] = true
+	}
+	var points []Breakpoint
+	for w := 1; w <= maxLoad; w++ {
+		if dp[n][w] == dp[n][w-1] {
+			continue
+		}
+		bp := Breakpoint{Capacity: w, Value: dp[n][w], Jump: dp[n][w] - dp[n][w-1]}
+		cur := make(map[string]bool)
+		for _, p := range backtrack(dp, pkgs, w) {
+			cur[p.Identifier] = true
+			if !prev[p.Identifier] {
+				bp.Added = append(bp.Added, p.Identifier)
+			}
+		}
+		for id := range prev {
+			if !cur[id] {
+				bp.Removed = append(bp.Removed, id)
+			}
+		}
+		sort.Strings(bp.Added)
+		sort.Strings(bp.Removed)
+		points = append(points, bp)
+		prev = cur
+	}
+	return points
+}
+
+// printCapacitySensitivity writes the breakpoint table, their count and the
+// average value gained per unit of capacity
+func printCapacitySensitivity(w io.Writer, pkgs []PackageMetadata, maxLoad int) {
+	points := CapacityBreakpoints(pkgs, maxLoad)
+	fmt.Fprintf(w, "%8s %6s %6s  %-12s %s\n", "capacity", "value", "jump", "added", "removed")
+	final := 0
+	for _, bp := range points {
+		removed := strings.Join(bp.Removed, ",")
+		if removed == "" {
+			removed = "-"
+		}
+		fmt.Fprintf(w, "%8s %6s %6s  %-12s %s\n", num(bp.Capacity), num(bp.Value), "+"+num(bp.Jump), strings.Join(bp.Added, ","), removed)
+		final = bp.Value
+	}
+	fmt.Fprintf(w, "breakpoints: %s\n", num(len(points)))
+	if maxLoad > 0 {
+		fmt.Fprintf(w, "average gain per unit capacity: %s\n", fnum(float64(final)/float64(maxLoad), 2))
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSweepCapacitiesParallelMatchesSequential(t *testing.T) {
+	pkgs := appendSynthetic(nil, 20, 50, 7)
+	newOptimizer := func() LoadOptimizer { return &PriorityBasedOptimizer{} }
+	sequential := SweepCapacities(pkgs, 0, 200, 1, newOptimizer, 1.0)
+	parallel := SweepCapacities(pkgs, 0, 200, 8, newOptimizer, 1.0)
+	for i := range sequential {
+		s, p := sequential[i], parallel[i]
+		if s.Capacity != i || p.Capacity != i || totalValue(s.Selected) != totalValue(p.Selected) {
+			t.Errorf("point %d: sequential capacity %d value %d, parallel capacity %d value %d",
+				i, s.Capacity, totalValue(s.Selected), p.Capacity, totalValue(p.Selected))
+		}
+	}
+}
+
+// BenchmarkSweepCapacities compares a 1000-capacity sweep on one worker
+// with pools of 2, 4 and 8; the speedup is bounded by GOMAXPROCS
+func BenchmarkSweepCapacities(b *testing.B) {
+	pkgs := appendSynthetic(nil, 40, 200, 1)
+	newOptimizer := func() LoadOptimizer { return &PriorityBasedOptimizer{} }
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				SweepCapacities(pkgs, 1, 1000, workers, newOptimizer, 1.0)
+			}
+		})
+	}
+}
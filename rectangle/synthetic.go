@@ -0,0 +1,22 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// appendSynthetic appends n packages S1..Sn with masses in [1, maxLoad] and
+// values in [1, 10*maxLoad], drawn from a generator seeded with seed
+func appendSynthetic(pkgs []PackageMetadata, n, maxLoad int, seed uint64) []PackageMetadata {
+	rng := rand.New(rand.NewSource(int64(seed)))
+	maxLoad = max(maxLoad, 1)
+	for i := 1; i <= n; i++ {
+		pkgs = append(pkgs, PackageMetadata{
+			Identifier:     "S" + strconv.Itoa(i),
+			MassConstraint: rng.Intn(maxLoad) + 1,
+			Valuation:      rng.Intn(10*maxLoad) + 1,
+		})
+	}
+	assignCosts(pkgs, seed)
+	return pkgs
+}
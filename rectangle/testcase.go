@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+)
+
+// writeTestCase emits the run as a gofmt'd entry for a table-driven test
+// with name, capacity, pkgs, want (sorted identifiers) and wantValue fields
+func writeTestCase(w io.Writer, name string, pkgs []PackageMetadata, ctx HeuristicContext, result OptimizationResult) error {
+	// format.Source needs a complete declaration, so wrap the entry and strip it afterwards
+	var b strings.Builder
+	b.WriteString("package p\n\nvar _ = []testCase{\n")
+	fmt.Fprintf(&b, "{\nname: %q,\ncapacity: %d,\npkgs: []PackageMetadata{\n", name, ctx.MaxLoad)
+	for _, p := range pkgs {
+		fmt.Fprintf(&b, "{Identifier: %q, MassConstraint: %d, Valuation: %d", p.Identifier, p.MassConstraint, p.Valuation)
+		if len(p.Dependencies) > 0 {
+			fmt.Fprintf(&b, ", Dependencies: %#v", p.Dependencies)
+		}
+		if len(p.ExcludedBy) > 0 {
+			fmt.Fprintf(&b, ", ExcludedBy: %#v", p.ExcludedBy)
+		}
+		b.WriteString("},\n")
+	}
+	fmt.Fprintf(&b, "},\nwant: %#v,\nwantValue: %d,\n},\n}\n", result.Selected, result.TotalValue)
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(src), "\n"), "\n")
+	for _, line := range lines[3 : len(lines)-1] {
+		if _, err := fmt.Fprintln(w, strings.TrimPrefix(line, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
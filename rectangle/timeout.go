@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// errOptimizerTimeout is reported when -timeout expires without a fallback
+var errOptimizerTimeout = errors.New("optimizer timed out; use -timeout-retry to fall back to greedy")
+
+// TimeoutOptimizer gives Inner at most Timeout to return. When it does not,
+// Fallback's selection is returned instead (nil without a Fallback) and
+// TimedOut is set. The abandoned Inner run keeps its goroutine until it
+// finishes or the process exits. A value on Signal ends the wait early, as
+// if the timeout had expired.
+type TimeoutOptimizer struct {
+	Inner    LoadOptimizer
+	Fallback LoadOptimizer
+	Timeout  time.Duration
+	Signal   <-chan os.Signal
+	TimedOut bool
+}
+
+// Optimize runs Inner against the deadline
+func (o *TimeoutOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	o.TimedOut = false
+	done := make(chan []PackageMetadata, 1)
+	go func() { done <- o.Inner.Optimize(pkgs, ctx) }()
+
+	timer := time.NewTimer(o.Timeout)
+	defer timer.Stop()
+	select {
+	case selected := <-done:
+		return selected
+	case <-timer.C:
+		warnf("optimizer timed out after %s", o.Timeout)
+	case sig := <-o.Signal:
+		warnf("optimizer interrupted by %s", sig)
+	}
+	o.TimedOut = true
+	if o.Fallback == nil {
+		return nil
+	}
+	infof("falling back to the greedy optimizer; the result is approximate")
+	return o.Fallback.Optimize(pkgs, ctx)
+}
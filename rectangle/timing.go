@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// phaseTiming is the wall time, and optionally the allocations, of one phase of a run
+type phaseTiming struct {
+	name      string
+	took      time.Duration
+	netAllocs int64 // mallocs minus frees
+	bytes     uint64
+}
+
+// phaseTimer records consecutive phases, each measured from the previous
+// checkpoint. With allocs set every checkpoint also reads runtime.MemStats,
+// which briefly stops the world.
+type phaseTimer struct {
+	checkpoint time.Time
+	allocs     bool
+	mem        runtime.MemStats
+	phases     []phaseTiming
+}
+
+// newPhaseTimer starts timing at the current instant
+func newPhaseTimer(allocs bool) *phaseTimer {
+	t := &phaseTimer{allocs: allocs, phases: make([]phaseTiming, 0, 8)}
+	if allocs {
+		runtime.ReadMemStats(&t.mem)
+	}
+	t.checkpoint = time.Now()
+	return t
+}
+
+// mark ends the current phase under name and starts the next one
+func (t *phaseTimer) mark(name string) {
+	p := phaseTiming{name: name, took: time.Since(t.checkpoint)}
+	if t.allocs {
+		before := t.mem
+		runtime.ReadMemStats(&t.mem)
+		p.netAllocs = int64(t.mem.Mallocs-before.Mallocs) - int64(t.mem.Frees-before.Frees)
+		p.bytes = t.mem.TotalAlloc - before.TotalAlloc
+	}
+	t.phases = append(t.phases, p)
+	t.checkpoint = time.Now()
+}
+
+// print writes one row per phase with its duration and share of the total
+func (t *phaseTimer) print(w io.Writer, title string) {
+	var total time.Duration
+	for _, p := range t.phases {
+		total += p.took
+	}
+	fmt.Fprintf(w, "%-20s %12s %7s\n", title, "duration", "share")
+	for _, p := range t.phases {
+		share := 0.0
+		if total > 0 {
+			share = 100 * float64(p.took) / float64(total)
+		}
+		fmt.Fprintf(w, "%-20s %12s %6s%%\n", p.name, p.took.Round(time.Microsecond), fnum(share, 1))
+	}
+	fmt.Fprintf(w, "%-20s %12s\n", "total", total.Round(time.Microsecond))
+}
+
+// printAllocations writes one row per phase with its net allocation count and bytes allocated
+func (t *phaseTimer) printAllocations(w io.Writer, title string) {
+	fmt.Fprintf(w, "%-20s %12s %12s\n", title, "net allocs", "bytes")
+	for _, p := range t.phases {
+		fmt.Fprintf(w, "%-20s %12s %12s\n", p.name, num(int(p.netAllocs)), num(int(p.bytes)))
+	}
+}
+
+// timeDPPhases replays the DP with the given table layout, timing allocation,
+// fill and backtracking separately; optimizers do not expose their own phases
+func timeDPPhases(layout func(rows, cols int) DPTable, pkgs []PackageMetadata, W int, allocs bool) *phaseTimer {
+	t := newPhaseTimer(allocs)
+	dp := layout(len(pkgs)+1, W+1)
+	t.mark("dp allocation")
+	fillTable(dp, pkgs, W)
+	t.mark("dp fill")
+	backtrackTable(dp, pkgs, W)
+	t.mark("backtracking")
+	return t
+}
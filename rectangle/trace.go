@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxTraceRows caps backtracking traces for large catalogs
+const maxTraceRows = 64
+
+// traceBacktrack replays the DP item recovery, printing each dp[i][w] == dp[i-1][w-wt]+val decision
+func traceBacktrack(out io.Writer, pkgs []PackageMetadata, W int) {
+	dp := buildTable(pkgs, W)
+	w := W
+	for i := len(pkgs); i > 0; i-- {
+		if len(pkgs)-i == maxTraceRows {
+			fmt.Fprintf(out, "backtrack: ... %d more items not shown\n", i)
+			return
+		}
+		pkg := pkgs[i-1]
+		if pkg.MassConstraint > w {
+			fmt.Fprintf(out, "backtrack i=%d %s w=%d: skip (mass %d > %d)\n", i, pkg.Identifier, w, pkg.MassConstraint, w)
+			continue
+		}
+		with := dp[i-1][w-pkg.MassConstraint] + pkg.Valuation
+		taken := dp[i][w] == with
+		fmt.Fprintf(out, "backtrack i=%d %s w=%d: dp[i][w]=%d dp[i-1][w-wt]+val=%d taken=%t\n", i, pkg.Identifier, w, dp[i][w], with, taken)
+		if taken {
+			w -= pkg.MassConstraint
+		}
+	}
+}
+
+// explainBacktrack prints the exact comparison made at each backtracking step
+func explainBacktrack(out io.Writer, pkgs []PackageMetadata, W int) {
+	dp := buildTable(pkgs, W)
+	w := W
+	for step, i := 1, len(pkgs); i > 0; step, i = step+1, i-1 {
+		if step > maxTraceRows {
+			fmt.Fprintf(out, "... %d more steps not shown\n", i)
+			return
+		}
+		pkg := pkgs[i-1]
+		fmt.Fprintf(out, "Step %d: Checking item %s (w=%d, v=%d) at capacity %d: ", step, pkg.Identifier, pkg.MassConstraint, pkg.Valuation, w)
+		if pkg.MassConstraint > w {
+			fmt.Fprintf(out, "w=%d > capacity %d... excluded\n", pkg.MassConstraint, w)
+			continue
+		}
+		with := dp[i-1][w-pkg.MassConstraint] + pkg.Valuation
+		if dp[i][w] == with {
+			fmt.Fprintf(out, "dp[%d][%d]=%d == dp[%d][%d]+%d=%d... included\n", i, w, dp[i][w], i-1, w-pkg.MassConstraint, pkg.Valuation, with)
+			w -= pkg.MassConstraint
+		} else {
+			fmt.Fprintf(out, "dp[%d][%d]=%d != dp[%d][%d]+%d=%d... excluded\n", i, w, dp[i][w], i-1, w-pkg.MassConstraint, pkg.Valuation, with)
+		}
+	}
+}
+
+// maxDiffRanges caps the capacity ranges listed per row by showDPRowDiff
+const maxDiffRanges = 32
+
+// showDPRowDiff prints, for each DP row, the capacities whose cell differs
+// from the row above, i.e. where taking the row's item improves the optimum;
+// consecutive capacities are merged into lo-hi ranges
+func showDPRowDiff(out io.Writer, pkgs []PackageMetadata, W int) {
+	dp := buildTable(pkgs, W)
+	for i := 1; i <= len(pkgs); i++ {
+		if i > maxTraceRows {
+			fmt.Fprintf(out, "... %d more rows not shown\n", len(pkgs)-maxTraceRows)
+			return
+		}
+		var ranges []string
+		for w := 0; w <= W; w++ {
+			if dp[i][w] == dp[i-1][w] {
+				continue
+			}
+			lo := w
+			for w < W && dp[i][w+1] != dp[i-1][w+1] {
+				w++
+			}
+			if lo == w {
+				ranges = append(ranges, fmt.Sprint(lo))
+			} else {
+				ranges = append(ranges, fmt.Sprintf("%d-%d", lo, w))
+			}
+		}
+		if len(ranges) > maxDiffRanges {
+			ranges = append(ranges[:maxDiffRanges], fmt.Sprintf("... %d more", len(ranges)-maxDiffRanges))
+		}
+		pkg := pkgs[i-1]
+		fmt.Fprintf(out, "Row %d (item %s, w=%d, v=%d): ", i, pkg.Identifier, pkg.MassConstraint, pkg.Valuation)
+		if len(ranges) == 0 {
+			fmt.Fprintln(out, "no cells changed")
+			continue
+		}
+		fmt.Fprintf(out, "changed cells at capacities [%s]\n", strings.Join(ranges, ", "))
+	}
+}
+
+// explainGreedyStep writes one line per greedy decision: the package, its
+// value/mass ratio and priority, and whether it was loaded or why not
+func explainGreedyStep(w io.Writer) func(GreedyStep) {
+	step := 0
+	return func(s GreedyStep) {
+		step++
+		decision := fmt.Sprintf("selected, %d capacity left", s.Remaining)
+		switch {
+		case s.Selected:
+		case s.LookaheadSkip:
+			decision = fmt.Sprintf("skipped: lookahead (value %d with it < %d without)", s.With, s.Without)
+		case s.Remaining == 0:
+			decision = "skipped: truck already at capacity"
+		default:
+			decision = fmt.Sprintf("skipped: too heavy (mass %d > %d left)", s.Package.MassConstraint, s.Remaining)
+		}
+		fmt.Fprintf(w, "step %d: %-3s ratio=%6.3f priority=%6.3f %s\n", step, s.Package.Identifier, ratio(s.Package), s.Priority, decision)
+	}
+}
+
+// annealBuckets is the number of probability ranges in the -explain-sa histogram
+const annealBuckets = 10
+
+// annealTrace logs simulated annealing moves and collects their acceptance probabilities
+type annealTrace struct {
+	w     io.Writer
+	probs []float64
+}
+
+// Record logs one proposed move
+func (t *annealTrace) Record(m AnnealMove) {
+	decision := "rejected"
+	if m.Accepted {
+		decision = "accepted"
+	}
+	fmt.Fprintf(t.w, "move %d: T=%8.3f value=%d proposed=%d delta=%+d p=%.3f %s\n",
+		len(t.probs)+1, m.Temperature, m.Value, m.Proposed, m.Delta, m.Probability, decision)
+	t.probs = append(t.probs, m.Probability)
+}
+
+// printHistogram writes an ASCII histogram of the recorded acceptance probabilities
+func (t *annealTrace) printHistogram() {
+	counts := make([]int, annealBuckets)
+	peak := 0
+	for _, p := range t.probs {
+		b := min(int(p*annealBuckets), annealBuckets-1)
+		counts[b]++
+		peak = max(peak, counts[b])
+	}
+	fmt.Fprintln(t.w, "acceptance probability histogram:")
+	for b, c := range counts {
+		bar := 0
+		if peak > 0 {
+			bar = c * 50 / peak
+		}
+		fmt.Fprintf(t.w, "  %.1f-%.1f | %s %d\n", float64(b)/annealBuckets, float64(b+1)/annealBuckets, strings.Repeat("#", bar), c)
+	}
+}
+
+// explainPriority prints one row per package with each computePriority term
+// and the resulting score
+func explainPriority(out io.Writer, pkgs []PackageMetadata, factor float64) {
+	fmt.Fprintf(out, "%-4s %9s %9s %9s %9s %9s\n", "pkg", "ratio", "sqrt(f)", "log1p(v)", "m^0.1", "priority")
+	for _, p := range pkgs {
+		t := priorityTerms(p, factor)
+		fmt.Fprintf(out, "%-4s %9.4f %9.4f %9.4f %9.4f %9.4f\n", p.Identifier, t.Ratio, t.SqrtFactor, t.Log1p, t.Power, computePriority(p, factor))
+	}
+}
+
+// explainImprovements reads the optimum over items 1..k from row k of one DP
+// table for each k, printing the item added, the change in value and the
+// selection it leads to
+func explainImprovements(out io.Writer, pkgs []PackageMetadata, W int) {
+	dp := buildTable(pkgs, W)
+	for k := 1; k <= len(pkgs); k++ {
+		if k > maxTraceRows {
+			fmt.Fprintf(out, "... %d more items not shown\n", len(pkgs)-k+1)
+			return
+		}
+		before, after := dp[k-1][W], dp[k][W]
+		selection := backtrack(dp[:k+1], pkgs[:k], W)
+		fmt.Fprintf(out, "step %d: +%s value %d -> %d (%+d) selection %s\n",
+			k, pkgs[k-1].Identifier, before, after, after-before, formatSelection(selection))
+	}
+}
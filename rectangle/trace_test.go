@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExplainGreedyLookaheadSkip(t *testing.T) {
+	var out bytes.Buffer
+	opt := &GreedyOptimizer{Lookahead: 3, OnStep: explainGreedyStep(&out)}
+	opt.Optimize(NewEmailBasedPackageGenerator().Generate("a@b.c"), HeuristicContext{MaxLoad: defaultMaxLoad, PriorityFactor: 1.0})
+	for _, line := range strings.Split(out.String(), "\n") {
+		// A (mass 10) fits in the 42 left after X; only the lookahead skips it
+		if strings.Contains(line, " A ") {
+			if !strings.Contains(line, "skipped: lookahead (value 190 with it < 210 without)") {
+				t.Errorf("A explained as %q, want a lookahead skip", line)
+			}
+			return
+		}
+	}
+	t.Fatalf("no step for A in:\n%s", out.String())
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+// MonotonicityError reports a capacity where the DP's final row decreases,
+// which no correct fill can produce: anything that fits in w fits in w+1
+type MonotonicityError struct {
+	W, Value, NextValue int
+}
+
+func (e *MonotonicityError) Error() string {
+	return fmt.Sprintf("DP not monotone in capacity: dp[n][%d]=%d > dp[n][%d]=%d (bug in the fill logic)",
+		e.W, e.Value, e.W+1, e.NextValue)
+}
+
+// checkMonotonicity verifies dp[n][w] <= dp[n][w+1] for every w in [0, W)
+func checkMonotonicity(dp DPTable, n, W int) error {
+	for w := 0; w < W; w++ {
+		if a, b := dp.Get(n, w), dp.Get(n, w+1); a > b {
+			return &MonotonicityError{W: w, Value: a, NextValue: b}
+		}
+	}
+	return nil
+}
+
+// checkBounds verifies greedy <= DP optimum <= LP relaxation bound; a
+// violation means a bug in one of the three computations
+func checkBounds(pkgs []PackageMetadata, ctx HeuristicContext) error {
+	greedy := totalValue((&GreedyOptimizer{}).Optimize(pkgs, ctx))
+	optimum := optimalValue(pkgs, ctx)
+	bound := UpperBound(pkgs, ctx.MaxLoad)
+	switch {
+	case float64(optimum) > bound+1e-9:
+		return fmt.Errorf("bound check: DP optimum %d exceeds LP upper bound %.4f at capacity %d (bug in the DP or the LP relaxation)",
+			optimum, bound, ctx.MaxLoad)
+	case greedy > optimum:
+		return fmt.Errorf("bound check: greedy value %d exceeds DP optimum %d at capacity %d (bug in the DP or the greedy selection)",
+			greedy, optimum, ctx.MaxLoad)
+	}
+	return nil
+}
+
+// checkDPSymmetry verifies that swapping two packages with identical mass
+// and value leaves every DP cell unchanged. The pairs are constructed: each
+// package in turn is duplicated at the end of the catalog and swapped with
+// its original position, so the check runs even without natural duplicates.
+func checkDPSymmetry(pkgs []PackageMetadata, W int) error {
+	n := len(pkgs) + 1
+	for i, p := range pkgs {
+		dup := p
+		dup.Identifier = p.Identifier + "'"
+		instance := append(slices.Clone(pkgs), dup)
+		swapped := slices.Clone(instance)
+		swapped[i], swapped[n-1] = swapped[n-1], swapped[i]
+
+		a, b := newDenseTable(n+1, W+1), newDenseTable(n+1, W+1)
+		fillTable(a, instance, W)
+		fillTable(b, swapped, W)
+		for row := 0; row <= n; row++ {
+			for w := 0; w <= W; w++ {
+				if a.Get(row, w) != b.Get(row, w) {
+					return fmt.Errorf("DP not symmetric: swapping %s with its duplicate changes dp[%d][%d] from %d to %d",
+						p.Identifier, row, w, a.Get(row, w), b.Get(row, w))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkOrderIndependence optimizes pkgs as given and randomly permuted by
+// seed, and reports an error if the two totals differ; an exact optimizer's
+// value never depends on item order
+func checkOrderIndependence(optimizer LoadOptimizer, pkgs []PackageMetadata, ctx HeuristicContext, seed int64) error {
+	shuffled := make([]PackageMetadata, len(pkgs))
+	copy(shuffled, pkgs)
+	rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	original := totalValue(optimizer.Optimize(pkgs, ctx))
+	permuted := totalValue(optimizer.Optimize(shuffled, ctx))
+	if original != permuted {
+		return fmt.Errorf("order dependence: value %d in catalog order, %d shuffled (seed %d, order %s)",
+			original, permuted, seed, strings.Join(identifiers(shuffled), ","))
+	}
+	return nil
+}
+
+// CheckOptimalityConditions tests a selection against two sufficient
+// conditions for 0/1 optimality and describes each violation:
+//
+//  1. no selected package has a negative shadow value: dropping it and
+//     refilling the freed capacity from the unselected packages gains nothing
+//  2. every unselected package either does not fit in the leftover capacity
+//     or has a lower value/mass ratio than the least dense selected package
+//
+// Condition 1 failing proves the selection suboptimal. Condition 2 is only
+// sufficient, so a DP optimum can break it when an exchange would not fit.
+func CheckOptimalityConditions(pkgs, selected []PackageMetadata, maxLoad int) []string {
+	inSelection := make(map[string]bool, len(selected))
+	for _, p := range selected {
+		inSelection[p.Identifier] = true
+	}
+	var unselected []PackageMetadata
+	for _, p := range pkgs {
+		if !inSelection[p.Identifier] {
+			unselected = append(unselected, p)
+		}
+	}
+	slack := maxLoad - totalMass(selected)
+
+	var violations []string
+	minDensity := -1.0
+	for _, p := range selected {
+		ctx := HeuristicContext{MaxLoad: slack + p.MassConstraint, PriorityFactor: 1.0}
+		if refill := optimalValue(unselected, ctx); refill > p.Valuation {
+			violations = append(violations, fmt.Sprintf("%s: negative shadow value: dropping it frees %d units that unselected packages fill with value %d > %d",
+				p.Identifier, ctx.MaxLoad, refill, p.Valuation))
+		}
+		if d := ratio(p); minDensity < 0 || d < minDensity {
+			minDensity = d
+		}
+	}
+	for _, p := range unselected {
+		switch {
+		case p.MassConstraint <= slack && p.Valuation > 0:
+			violations = append(violations, fmt.Sprintf("%s: unselected but fits in the %d units left", p.Identifier, slack))
+		case p.MassConstraint > slack && minDensity >= 0 && ratio(p) > minDensity:
+			violations = append(violations, fmt.Sprintf("%s: unselected with value/mass %.3f above the lowest selected %.3f", p.Identifier, ratio(p), minDensity))
+		}
+	}
+	return violations
+}
+
+// printOptimalityConditions reports the violated conditions, if any
+func printOptimalityConditions(w io.Writer, pkgs, selected []PackageMetadata, maxLoad int) {
+	violations := CheckOptimalityConditions(pkgs, selected, maxLoad)
+	if len(violations) == 0 {
+		fmt.Fprintln(w, "optimality conditions: all hold")
+		return
+	}
+	fmt.Fprintf(w, "optimality conditions: %d violations\n", len(violations))
+	for _, v := range violations {
+		fmt.Fprintf(w, "  %s\n", v)
+	}
+}
+
+// ValidateResult checks a result against the catalog it came from: every
+// selected identifier exists and appears once, the totals match the
+// packages and the mass fits the capacity
+func ValidateResult(pkgs []PackageMetadata, result OptimizationResult) error {
+	byID := make(map[string]PackageMetadata, len(pkgs))
+	for _, p := range pkgs {
+		byID[p.Identifier] = p
+	}
+	seen := make(map[string]bool, len(result.Selected))
+	mass, value := 0, 0
+	for _, id := range result.Selected {
+		p, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("invalid result: selected package %s is not in the catalog", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("invalid result: package %s selected twice", id)
+		}
+		seen[id] = true
+		mass += p.MassConstraint
+		value += p.Valuation
+	}
+	switch {
+	case mass != result.TotalMass:
+		return fmt.Errorf("invalid result: total mass %d, packages weigh %d", result.TotalMass, mass)
+	case value != result.TotalValue:
+		return fmt.Errorf("invalid result: total value %d, packages are worth %d", result.TotalValue, value)
+	case mass > result.Capacity:
+		return fmt.Errorf("invalid result: mass %d exceeds capacity %d", mass, result.Capacity)
+	}
+	return nil
+}
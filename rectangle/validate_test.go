@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderIndependence(t *testing.T) {
+	generator := NewEmailBasedPackageGenerator()
+	for _, email := range []string{"test@example.com", "a@b.c", "someone@example.org"} {
+		pkgs := appendSynthetic(generator.Generate(email), 12, 40, computeSeed(email))
+		for _, W := range []int{0, 50, 150} {
+			ctx := HeuristicContext{MaxLoad: W, PriorityFactor: 1.0}
+			for name, opt := range map[string]LoadOptimizer{
+				"dp":      &PriorityBasedOptimizer{},
+				"lazy":    &LazyDP{},
+				"reverse": &PriorityBasedOptimizer{ForwardBacktrack: true},
+			} {
+				for seed := int64(1); seed <= 10; seed++ {
+					if err := checkOrderIndependence(opt, pkgs, ctx, seed); err != nil {
+						t.Errorf("%s, capacity %d, %s: %v", email, W, name, err)
+					}
+				}
+			}
+		}
+	}
+
+	// taking packages in catalog order while they fit is order dependent,
+	// which is what the check is there to catch
+	tied := []PackageMetadata{
+		{Identifier: "A", MassConstraint: 6, Valuation: 60},
+		{Identifier: "B", MassConstraint: 5, Valuation: 50},
+		{Identifier: "C", MassConstraint: 5, Valuation: 50},
+	}
+	ctx := HeuristicContext{MaxLoad: 10}
+	failed := false
+	for seed := int64(1); seed <= 20 && !failed; seed++ {
+		failed = checkOrderIndependence(firstFit{}, tied, ctx, seed) != nil
+	}
+	if !failed {
+		t.Error("no shuffle exposed first fit's dependence on package order")
+	}
+}
+
+// firstFit loads packages in the order given while they fit
+type firstFit struct{}
+
+func (firstFit) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	var res []PackageMetadata
+	load := 0
+	for _, p := range pkgs {
+		if load+p.MassConstraint <= ctx.MaxLoad {
+			res = append(res, p)
+			load += p.MassConstraint
+		}
+	}
+	return res
+}
+
+func TestMonotonicity(t *testing.T) {
+	for seed := uint64(1); seed <= 20; seed++ {
+		pkgs := appendSynthetic(nil, 15, 30, seed)
+		for name, layout := range dpLayouts {
+			dp := layout(len(pkgs)+1, 101)
+			fillTable(dp, pkgs, 100)
+			if err := checkMonotonicity(dp, len(pkgs), 100); err != nil {
+				t.Errorf("seed %d, %s layout: %v", seed, name, err)
+			}
+		}
+	}
+
+	// a fill that lost an item at one capacity is caught at that capacity
+	pkgs := []PackageMetadata{{Identifier: "A", MassConstraint: 3, Valuation: 10}}
+	dp := newDenseTable(2, 6)
+	fillTable(dp, pkgs, 5)
+	dp.Set(1, 4, 0)
+	var mono *MonotonicityError
+	if err := checkMonotonicity(dp, 1, 5); !errors.As(err, &mono) || mono.W != 3 || mono.Value != 10 || mono.NextValue != 0 {
+		t.Errorf("corrupted table: %v, want dp[n][3]=10 > dp[n][4]=0", err)
+	}
+
+	opts, err := parseOptions([]string{"test@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.checkMonotone {
+		t.Error("-check-monotonicity is off under go test")
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+// ValueCapOptimizer lets Inner optimize with every valuation clamped to at
+// most Cap, then reports the chosen packages with their original values
+type ValueCapOptimizer struct {
+	Inner LoadOptimizer
+	Cap   int
+}
+
+// Optimize runs Inner on the capped catalog and maps its selection back
+func (o *ValueCapOptimizer) Optimize(pkgs []PackageMetadata, ctx HeuristicContext) []PackageMetadata {
+	capped := make([]PackageMetadata, len(pkgs))
+	original := make(map[string]PackageMetadata, len(pkgs))
+	for i, p := range pkgs {
+		original[p.Identifier] = p
+		capped[i] = p
+		capped[i].Valuation = min(p.Valuation, o.Cap)
+	}
+	selected := o.Inner.Optimize(capped, ctx)
+	if selected == nil {
+		return nil
+	}
+	out := make([]PackageMetadata, len(selected))
+	for i, p := range selected {
+		out[i] = original[p.Identifier]
+	}
+	return out
+}
+
+// cappedValue is the total value of pkgs with each valuation clamped to at
+// most limit, the objective ValueCapOptimizer's Inner maximizes
+func cappedValue(pkgs []PackageMetadata, limit int) int {
+	sum := 0
+	for _, p := range pkgs {
+		sum += min(p.Valuation, limit)
+	}
+	return sum
+}
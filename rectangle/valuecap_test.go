@@ -0,0 +1,33 @@
+package main
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestValueCapOptimizer(t *testing.T) {
+	pkgs := []PackageMetadata{
+		{Identifier: "A", MassConstraint: 10, Valuation: 120},
+		{Identifier: "B", MassConstraint: 6, Valuation: 50},
+		{Identifier: "C", MassConstraint: 6, Valuation: 45},
+	}
+	ctx := HeuristicContext{MaxLoad: 12}
+	tests := []struct {
+		cap       int
+		want      []string
+		wantValue int // reported with the original valuations
+	}{
+		{cap: 1000, want: []string{"A"}, wantValue: 120},
+		{cap: 100, want: []string{"A"}, wantValue: 120},    // capped A still beats B+C
+		{cap: 90, want: []string{"B", "C"}, wantValue: 95}, // capped A 90 < 95
+	}
+	for _, tt := range tests {
+		selected := (&ValueCapOptimizer{Inner: &PriorityBasedOptimizer{}, Cap: tt.cap}).Optimize(pkgs, ctx)
+		ids := identifiers(selected)
+		sort.Strings(ids)
+		if !slices.Equal(ids, tt.want) || totalValue(selected) != tt.wantValue {
+			t.Errorf("cap %d: selected %v worth %d, want %v worth %d", tt.cap, ids, totalValue(selected), tt.want, tt.wantValue)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// generatorVersion identifies how EmailBasedPackageGenerator derives the
+// dynamic packages from an email; bump it whenever computeSeed or the X/Y
+// formulas change, since that changes every answer
+const generatorVersion = "1"
+
+// version and commit are set at release build time; otherwise they fall back
+// to the module and VCS information embedded by the go tool:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=abc1234"
+var (
+	version string
+	commit  string
+)
+
+// buildVersion returns the release version and commit, filled in from
+// debug.ReadBuildInfo when not set via ldflags
+func buildVersion() (string, string) {
+	v, c := version, commit
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "" {
+			v = info.Main.Version
+		}
+		modified := false
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if c == "" {
+					c = s.Value
+				}
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+		if modified && c != "" && commit == "" {
+			c += "-dirty"
+		}
+	}
+	if v == "" {
+		v = "(devel)"
+	}
+	if c == "" {
+		c = "unknown"
+	}
+	return v, c
+}
+
+// printVersion writes the build and generator versions
+func printVersion(w io.Writer) {
+	v, c := buildVersion()
+	fmt.Fprintf(w, "version:   %s\n", v)
+	fmt.Fprintf(w, "commit:    %s\n", c)
+	fmt.Fprintf(w, "generator: v%s\n", generatorVersion)
+}